@@ -1,12 +1,36 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
+
+	"github.com/Linux-DEX/azstorecli/pkg/cli"
 	"github.com/Linux-DEX/azstorecli/pkg/ui"
 )
 
 func main() {
-	if err := ui.RunApp(); err != nil {
+	args := os.Args[1:]
+	if cli.IsCommand(args) {
+		if err := cli.Run(args); err != nil {
+			exit(err)
+		}
+		return
+	}
+	cfg, err := cli.ExplorerConfig(args)
+	if err != nil {
+		exit(err)
+	}
+	if err := ui.RunApp(cfg); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// exit reports err, unless the usage text already explained it, and exits 1.
+func exit(err error) {
+	if !errors.Is(err, cli.ErrUsage) {
+		log.SetFlags(0)
+		log.Printf("azstorecli: %v", err)
+	}
+	os.Exit(1)
+}
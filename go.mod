@@ -2,8 +2,9 @@ module github.com/Linux-DEX/azstorecli
 
 go 1.25.3
 
+require github.com/awesome-gocui/gocui v1.1.0
+
 require (
-	github.com/awesome-gocui/gocui v1.1.0 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/charmbracelet/colorprofile v0.2.3-0.20250311203215-f60798e515dc // indirect
 	github.com/charmbracelet/x/ansi v0.10.1 // indirect
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+var azuriteCommands = map[string]command{
+	"start": {
+		short:  "create or start the Azurite instance",
+		run:    azuriteStart,
+		launch: true,
+	},
+	"stop": {
+		short: "stop the Azurite instance",
+		run:   azuriteStop,
+	},
+	"status": {
+		short:  "print the Azurite container or process ID, state and service health",
+		run:    azuriteStatus,
+		output: true,
+	},
+	"list": {
+		short:  "list the configured Azurite instances and their state",
+		run:    azuriteList,
+		output: true,
+	},
+	"reset": {
+		args:  "[blob|queue|table...]",
+		short: "delete the instance's data (all services by default) and restart it",
+		run:   azuriteReset,
+		flags: func(fs *flag.FlagSet) {
+			fs.String("snapshot", "", "first save the data as a .tar.gz at this path")
+			fs.Bool("yes", false, "do not ask for confirmation")
+		},
+	},
+	"logs": {
+		short: "print the Azurite logs",
+		run:   azuriteLogs,
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("f", false, "follow the log output")
+		},
+	},
+}
+
+func azuriteStart(c *env, args []string) error {
+	if err := want(args, 0, 0); err != nil {
+		return err
+	}
+	id, err := storage.EnsureAzurite(c.azurite, func(msg string) { fmt.Fprint(c.stderr, msg) })
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, id)
+	return nil
+}
+
+func azuriteStop(c *env, args []string) error {
+	if err := want(args, 0, 0); err != nil {
+		return err
+	}
+	return storage.StopAzuriteContainer(c.azurite)
+}
+
+func azuriteStatus(c *env, args []string) error {
+	if err := want(args, 0, 0); err != nil {
+		return err
+	}
+	id, state, err := storage.AzuriteStatus(c.azurite)
+	if err != nil {
+		return err
+	}
+	t := output.Table{Columns: []string{"id", "state"}}
+	row := []interface{}{id, state}
+	if id == "" {
+		row = []interface{}{nil, "not created"}
+	}
+	if state == "running" {
+		// Probe each service once, as a running container may still be starting
+		for _, h := range storage.NewClient(c.azurite.Account()).Probe(context.Background()) {
+			t.Columns = append(t.Columns, h.Service)
+			if h.Ready {
+				row = append(row, "ready")
+			} else {
+				row = append(row, h.Err.Error())
+			}
+		}
+	}
+	t.Rows = [][]interface{}{row}
+	return c.write(t)
+}
+
+func azuriteList(c *env, args []string) error {
+	if err := want(args, 0, 0); err != nil {
+		return err
+	}
+	t := output.Table{Columns: []string{"instance", "runtime", "container", "blobPort", "queuePort", "tablePort", "state", "active"}}
+	for _, inst := range c.config.Instances {
+		_, state, err := storage.AzuriteStatus(inst)
+		switch {
+		case err != nil:
+			state = "unknown"
+		case state == "":
+			state = "not created"
+		}
+		runtime := "none"
+		if rt, err := storage.RuntimeFor(inst); err == nil {
+			runtime = rt.Name()
+		}
+		t.Rows = append(t.Rows, []interface{}{inst.Name, runtime, inst.ContainerName,
+			inst.BlobPort, inst.QueuePort, inst.TablePort, state, inst.Name == c.azurite.Name})
+	}
+	return c.write(t)
+}
+
+func azuriteReset(c *env, args []string) error {
+	if err := want(args, 0, len(storage.AzuriteServices)); err != nil {
+		return err
+	}
+	what := "all data"
+	if len(args) > 0 {
+		what = "the " + strings.Join(args, ", ") + " data"
+	}
+	if !c.boolFlag("yes") {
+		fmt.Fprintf(c.stderr, "Delete %s of Azurite instance %s? [y/N] ", what, c.azurite.Name)
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return fmt.Errorf("reset cancelled")
+		}
+	}
+	return storage.ResetAzuriteData(c.azurite, args, c.flag("snapshot"), func(msg string) { fmt.Fprint(c.stderr, msg) })
+}
+
+func azuriteLogs(c *env, args []string) error {
+	if err := want(args, 0, 0); err != nil {
+		return err
+	}
+	return storage.WriteAzuriteLogs(c.azurite, c.stdout, c.boolFlag("f"))
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+var blobCommands = map[string]command{
+	"ls": {
+		args:   "[container[/prefix]]",
+		short:  "list containers, or the blobs in a container",
+		run:    blobList,
+		output: true,
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("recursive", false, "list blobs under every virtual directory")
+		},
+	},
+	"put": {
+		args:  "<local-file> <container>[/blob]",
+		short: "upload a file to a block blob",
+		run:   blobPut,
+		flags: func(fs *flag.FlagSet) {
+			fs.String("content-type", "", "Content-Type of the blob (default from the file extension)")
+		},
+	},
+	"get": {
+		args:  "<container>/<blob> [local-file]",
+		short: "download a blob",
+		run:   blobGet,
+	},
+	"rm": {
+		args:  "<container>/<blob>...",
+		short: "delete blobs",
+		run:   blobRemove,
+	},
+}
+
+func blobList(c *env, args []string) error {
+	if err := want(args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		names, err := c.client.ListContainers()
+		if err != nil {
+			return err
+		}
+		return c.write(output.Names("name", names))
+	}
+	container, prefix := splitPath(args[0])
+	if prefix != "" && !strings.HasSuffix(prefix, storage.BlobDelimiter) {
+		prefix += storage.BlobDelimiter
+	}
+	var entries []storage.Entry
+	err := walk(func(prefix, token string) (storage.Page, error) {
+		return c.client.ListBlobs(container, prefix, token)
+	}, prefix, c.boolFlag("recursive"), func(e storage.Entry) {
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return err
+	}
+	return c.write(output.Entries(entries))
+}
+
+// walk lists every page under prefix, descending into directories when
+// recursive is set, and calls fn for each entry.
+func walk(list func(prefix, token string) (storage.Page, error), prefix string, recursive bool, fn func(storage.Entry)) error {
+	token := ""
+	for {
+		page, err := list(prefix, token)
+		if err != nil {
+			return err
+		}
+		for _, e := range page.Entries {
+			if e.IsDir && recursive {
+				if err := walk(list, e.Path, true, fn); err != nil {
+					return err
+				}
+				continue
+			}
+			fn(e)
+		}
+		if page.Next == "" {
+			return nil
+		}
+		token = page.Next
+	}
+}
+
+func blobPut(c *env, args []string) error {
+	if err := want(args, 2, 2); err != nil {
+		return err
+	}
+	local := args[0]
+	container, blob := splitPath(args[1])
+	if blob == "" || strings.HasSuffix(blob, storage.BlobDelimiter) {
+		blob += filepath.Base(local)
+	}
+	opts := storage.DefaultUploadOptions()
+	opts.ContentType = c.flag("content-type")
+	return c.client.UploadFile(container, blob, local, opts, nil)
+}
+
+func blobGet(c *env, args []string) error {
+	if err := want(args, 1, 2); err != nil {
+		return err
+	}
+	container, blob := splitPath(args[0])
+	if blob == "" {
+		return fmt.Errorf("missing blob name in %q", args[0])
+	}
+	local := filepath.Base(blob)
+	if len(args) == 2 {
+		local = args[1]
+	}
+	return c.client.DownloadFile(container, blob, local, nil)
+}
+
+func blobRemove(c *env, args []string) error {
+	if err := want(args, 1, noMax); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		container, blob := splitPath(arg)
+		if blob == "" {
+			return fmt.Errorf("missing blob name in %q", arg)
+		}
+		if err := c.client.DeleteBlob(container, blob); err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+	}
+	return nil
+}
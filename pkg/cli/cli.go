@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+// ErrUsage is returned when the arguments do not name a valid command; the
+// usage text has already been printed.
+var ErrUsage = errors.New("usage error")
+
+// command is one "<group> <name>" subcommand.
+type command struct {
+	args   string // positional arguments for the usage text
+	short  string
+	run    func(c *env, args []string) error
+	flags  func(fs *flag.FlagSet) // registers command-specific flags, may be nil
+	output bool                   // prints a table and takes --output
+	launch bool                   // takes the Azurite launch option flags
+}
+
+// env is what a command runs with.
+type env struct {
+	client  *storage.Client
+	config  storage.Config
+	azurite storage.AzuriteConfig // the selected instance
+	flags   *flag.FlagSet
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	format  output.Format
+}
+
+var groups = map[string]map[string]command{
+	"blob":    blobCommands,
+	"queue":   queueCommands,
+	"table":   tableCommands,
+	"share":   shareCommands,
+	"azurite": azuriteCommands,
+}
+
+// Run executes the subcommand in args, e.g. ["blob", "ls", "mycontainer"].
+func Run(args []string) error {
+	return run(args, os.Stdin, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		usage(stdout, "")
+		return nil
+	}
+	if len(args) < 2 || groups[args[0]] == nil {
+		group := ""
+		if len(args) > 0 && groups[args[0]] != nil {
+			group = args[0]
+		}
+		usage(stderr, group)
+		return ErrUsage
+	}
+	group, name := args[0], args[1]
+	cmd, ok := groups[group][name]
+	if !ok {
+		usage(stderr, group)
+		return ErrUsage
+	}
+
+	conf, cfg, err := loadInstance(args[2:])
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet(group+" "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFlags(fs, conf, cfg)
+	if cmd.launch {
+		launchFlags(fs, &cfg)
+	}
+	connStr := new(string)
+	if group != "azurite" {
+		fs.StringVar(connStr, "connection-string", "", "storage connection string (default $"+storage.ConnectionStringEnv+" or Azurite)")
+	}
+	if cmd.output {
+		help := "output format: " + formatNames()
+		fs.String("output", string(output.Text), help)
+		fs.String("o", string(output.Text), "shorthand for --output")
+	}
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: azstorecli %s %s [flags] %s\n\n%s\n\nFlags:\n", group, name, cmd.args, cmd.short)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args[2:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return ErrUsage
+	}
+
+	conf.Replace(cfg)
+	c := &env{config: conf, azurite: cfg, flags: fs, stdin: stdin, stdout: stdout, stderr: stderr}
+	if cmd.output {
+		if c.format, err = selectedFormat(fs); err != nil {
+			return err
+		}
+	}
+	if group != "azurite" {
+		account, err := storage.DefaultAccount(cfg)
+		if *connStr != "" {
+			account, err = storage.ParseConnectionString(*connStr)
+		}
+		if err != nil {
+			return err
+		}
+		c.client = storage.NewClient(account)
+	}
+	return cmd.run(c, positional)
+}
+
+// flag returns the value of one of the command's flags.
+func (c *env) flag(name string) string {
+	return c.flags.Lookup(name).Value.String()
+}
+
+func (c *env) boolFlag(name string) bool {
+	return c.flags.Lookup(name).Value.(flag.Getter).Get().(bool)
+}
+
+func (c *env) durationFlag(name string) time.Duration {
+	return c.flags.Lookup(name).Value.(flag.Getter).Get().(time.Duration)
+}
+
+// write prints t in the format chosen with --output.
+func (c *env) write(t output.Table) error {
+	return output.Write(c.stdout, c.format, t)
+}
+
+// selectedFormat returns the --output or -o format, whichever was given.
+func selectedFormat(fs *flag.FlagSet) (output.Format, error) {
+	value := fs.Lookup("output").Value.String()
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "o" {
+			value = f.Value.String()
+		}
+	})
+	return output.ParseFormat(value)
+}
+
+func formatNames() string {
+	names := make([]string, len(output.Formats))
+	for i, f := range output.Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseInterspersed parses fs from args allowing flags after positional
+// arguments, and returns the positional ones. "--" ends flag parsing.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// Parse consumes a "--" that follows a flag, and stops before one
+		// that does not
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// noMax is the max of want for commands taking any number of arguments.
+const noMax = -1
+
+// want checks the number of positional arguments.
+func want(args []string, min, max int) error {
+	if max == noMax && len(args) < min {
+		return fmt.Errorf("expected at least %d arguments, got %d (see --help)", min, len(args))
+	}
+	if max != noMax && (len(args) < min || len(args) > max) {
+		return fmt.Errorf("expected %d to %d arguments, got %d (see --help)", min, max, len(args))
+	}
+	return nil
+}
+
+// splitPath splits "container/some/blob" into "container" and "some/blob".
+func splitPath(s string) (string, string) {
+	name, rest, _ := strings.Cut(s, "/")
+	return name, rest
+}
+
+func usage(w io.Writer, only string) {
+	fmt.Fprintln(w, "Usage: azstorecli [flags]                      start the interactive explorer")
+	fmt.Fprintln(w, "       azstorecli <group> <command> [flags] [args]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		if only == "" || g == only {
+			names = append(names, g)
+		}
+	}
+	sort.Strings(names)
+	for _, g := range names {
+		cmds := make([]string, 0, len(groups[g]))
+		for name := range groups[g] {
+			cmds = append(cmds, name)
+		}
+		sort.Strings(cmds)
+		for _, name := range cmds {
+			cmd := groups[g][name]
+			fmt.Fprintf(w, "  %-44s %s\n", g+" "+name+" "+cmd.args, cmd.short)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run a command with --help for its flags.")
+	if only == "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Explorer flags (also read from the config file):")
+		printExplorerFlags(w)
+	}
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		args           []string
+		wantPositional []string
+		wantRecursive  bool
+		wantPrefix     string
+	}{
+		{nil, nil, false, ""},
+		{[]string{"c"}, []string{"c"}, false, ""},
+		{[]string{"--recursive", "c"}, []string{"c"}, true, ""},
+		{[]string{"c", "--recursive"}, []string{"c"}, true, ""},
+		{[]string{"c", "--prefix", "logs/", "d", "-recursive"}, []string{"c", "d"}, true, "logs/"},
+		{[]string{"c", "--prefix=a", "--", "--recursive", "-x"}, []string{"c", "--recursive", "-x"}, false, "a"},
+		{[]string{"c", "--", "-x"}, []string{"c", "-x"}, false, ""},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		recursive := fs.Bool("recursive", false, "")
+		prefix := fs.String("prefix", "", "")
+		positional, err := parseInterspersed(fs, tt.args)
+		if err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(positional, tt.wantPositional) || *recursive != tt.wantRecursive || *prefix != tt.wantPrefix {
+			t.Errorf("%q: positional %q, recursive %v, prefix %q; want %q, %v, %q",
+				tt.args, positional, *recursive, *prefix, tt.wantPositional, tt.wantRecursive, tt.wantPrefix)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := parseInterspersed(fs, []string{"c", "--nope"}); err == nil {
+		t.Error("an unknown flag after a positional argument was accepted")
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+		want string
+	}{
+		{[]string{"--instance", "second"}, "instance", "second"},
+		{[]string{"--instance=second"}, "instance", "second"},
+		{[]string{"-instance", "second"}, "instance", "second"},
+		{[]string{"ls", "c", "--runtime", "podman"}, "runtime", "podman"},
+		{[]string{"ls", "--runtime=azurite", "c"}, "runtime", "azurite"},
+		{[]string{"--runtime="}, "runtime", ""},
+		{[]string{"--runtime"}, "runtime", ""},
+		{[]string{"--instances", "x"}, "instance", ""},
+		{[]string{"instance", "x"}, "instance", ""},
+		{[]string{"--", "--instance", "x"}, "instance", ""},
+	}
+	for _, tt := range tests {
+		if got := flagValue(tt.args, tt.name); got != tt.want {
+			t.Errorf("flagValue(%q, %s) = %q, want %q", tt.args, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWant(t *testing.T) {
+	tests := []struct {
+		n, min, max int
+		want        string
+	}{
+		{1, 1, 1, ""},
+		{0, 0, 1, ""},
+		{2, 1, 1, "expected 1 to 1 arguments, got 2 (see --help)"},
+		{0, 2, 2, "expected 2 to 2 arguments, got 0 (see --help)"},
+		{5, 1, noMax, ""},
+		{0, 1, noMax, "expected at least 1 arguments, got 0 (see --help)"},
+	}
+	for _, tt := range tests {
+		err := want(make([]string, tt.n), tt.min, tt.max)
+		if got := fmt.Sprint(err); (err == nil && tt.want != "") || (err != nil && got != tt.want) {
+			t.Errorf("want(%d args, %d, %d) = %v, want %q", tt.n, tt.min, tt.max, err, tt.want)
+		}
+	}
+}
+
+func TestDurationFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Duration("visibility", 0, "")
+	fs.Duration("ttl", 0, "")
+	fs.Bool("recursive", false, "")
+	if err := fs.Parse([]string{"--visibility", "1m30s", "--ttl=-1s", "--recursive"}); err != nil {
+		t.Fatal(err)
+	}
+	c := &env{flags: fs}
+	if got := c.durationFlag("visibility"); got != 90*time.Second {
+		t.Errorf("visibility = %v", got)
+	}
+	if got := c.durationFlag("ttl"); got != -time.Second {
+		t.Errorf("ttl = %v", got)
+	}
+	if !c.boolFlag("recursive") {
+		t.Error("recursive = false")
+	}
+}
+
+func TestSelectedFormat(t *testing.T) {
+	tests := []struct {
+		args []string
+		want output.Format
+	}{
+		{nil, output.Text},
+		{[]string{"--output", "json"}, output.JSON},
+		{[]string{"-o", "csv"}, output.CSV},
+		{[]string{"-o=yaml", "--output=json"}, output.YAML},
+		{[]string{"--output=jsonl", "-o", "table"}, output.Text},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("output", string(output.Text), "")
+		fs.String("o", string(output.Text), "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := selectedFormat(fs); err != nil || got != tt.want {
+			t.Errorf("%q: format %q, %v; want %q", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func writeConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"instances": {"second": {"blobPort": 20000, "queuePort": 20001, "tablePort": 20002, "runtime": "docker"}}, "active": "second"}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadInstance(t *testing.T) {
+	config := writeConfig(t)
+	tests := []struct {
+		args        []string
+		wantName    string
+		wantRuntime string
+		wantErr     string
+	}{
+		{[]string{"--config", config}, "second", "docker", ""},
+		{[]string{"--config=" + config, "--instance", "default"}, "default", "", ""},
+		{[]string{"c", "--runtime", "podman", "--config", config}, "second", "podman", ""},
+		{[]string{"--config", config, "--instance=nope", "--runtime", "lxc"}, "", "", `no Azurite instance "nope" in the config file`},
+	}
+	for _, tt := range tests {
+		_, cfg, err := loadInstance(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%q: error %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || cfg.Name != tt.wantName || cfg.Runtime != tt.wantRuntime {
+			t.Errorf("%q: instance %s with runtime %q, %v; want %s with %q", tt.args, cfg.Name, cfg.Runtime, err, tt.wantName, tt.wantRuntime)
+		}
+	}
+}
+
+func TestRunQueueCommands(t *testing.T) {
+	t.Setenv(storage.AzuriteConfigEnv, filepath.Join(t.TempDir(), "none.json"))
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.Method+" "+r.URL.RawQuery)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		fmt.Fprint(w, `<EnumerationResults><Queues><Queue><Name>jobs</Name></Queue></Queues><NextMarker/></EnumerationResults>`)
+	}))
+	defer srv.Close()
+	connStr := "AccountName=devstoreaccount1;AccountKey=a2V5;QueueEndpoint=" + srv.URL + "/devstoreaccount1"
+
+	tests := []struct {
+		args       []string
+		wantQuery  string
+		wantStdout string
+	}{
+		{[]string{"queue", "send", "jobs", "hi", "--visibility", "1m30s", "--ttl=-1s"}, "POST messagettl=-1&visibilitytimeout=90", ""},
+		{[]string{"queue", "send", "jobs", "hi"}, "POST ", ""},
+		{[]string{"queue", "peek"}, "GET comp=list", "NAME\njobs\n"},
+		{[]string{"queue", "peek", "-o", "csv"}, "GET comp=list", "name\njobs\n"},
+		{[]string{"queue", "peek", "--output=jsonl"}, "GET comp=list", `{"name":"jobs"}` + "\n"},
+	}
+	for _, tt := range tests {
+		queries = nil
+		var stdout, stderr strings.Builder
+		args := append(tt.args, "--connection-string", connStr)
+		if err := run(args, strings.NewReader(""), &stdout, &stderr); err != nil {
+			t.Errorf("%q: %v (%s)", tt.args, err, stderr.String())
+			continue
+		}
+		if len(queries) != 1 || queries[0] != tt.wantQuery {
+			t.Errorf("%q: requests %q, want %q", tt.args, queries, tt.wantQuery)
+		}
+		if stdout.String() != tt.wantStdout {
+			t.Errorf("%q: stdout %q, want %q", tt.args, stdout.String(), tt.wantStdout)
+		}
+	}
+
+	if err := run([]string{"queue", "send", "jobs"}, nil, io.Discard, io.Discard); err == nil || errors.Is(err, ErrUsage) {
+		t.Errorf("send with one argument = %v, want an argument count error", err)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+// IsCommand reports whether args name a subcommand or ask for help, as
+// opposed to being flags for the interactive explorer.
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	return !strings.HasPrefix(args[0], "-") || args[0] == "-h" || args[0] == "--help"
+}
+
+// ExplorerConfig parses the interactive explorer's flags: --config,
+// --instance and the Azurite launch options, which override the settings of
+// the selected instance. The returned Config has that instance Active.
+func ExplorerConfig(args []string) (storage.Config, error) {
+	conf, cfg, err := loadInstance(args)
+	if err != nil {
+		return conf, err
+	}
+	fs := flag.NewFlagSet("azstorecli", flag.ContinueOnError)
+	configFlags(fs, conf, cfg)
+	launchFlags(fs, &cfg)
+	fs.BoolVar(&conf.LeaveRunning, "leave-running", conf.LeaveRunning, "leave the Azurite instances the explorer started running on quit")
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() { usage(os.Stderr, "") }
+	if err := fs.Parse(args); err != nil {
+		return conf, ErrUsage
+	}
+	if fs.NArg() > 0 {
+		usage(os.Stderr, "")
+		return conf, ErrUsage
+	}
+	conf.Replace(cfg)
+	conf.Active = cfg.Name
+	return conf, conf.Validate()
+}
+
+// loadInstance reads the config file named by --config in args and returns
+// it with the instance chosen by --instance, or the file's active one, run
+// with the --runtime given. The flags are looked up before parsing so the
+// file can supply the defaults of the launch flags.
+func loadInstance(args []string) (storage.Config, storage.AzuriteConfig, error) {
+	conf, err := storage.LoadConfig(flagValue(args, "config"))
+	if err != nil {
+		return conf, storage.AzuriteConfig{}, err
+	}
+	name := flagValue(args, "instance")
+	if name == "" {
+		name = conf.Active
+	}
+	cfg, err := conf.Instance(name)
+	if err != nil {
+		return conf, cfg, err
+	}
+	if rt := flagValue(args, "runtime"); rt != "" {
+		cfg.Runtime = rt
+	}
+	return conf, cfg, nil
+}
+
+// configFlags registers --config, --instance and --runtime. They were already
+// applied by loadInstance; they are registered so parsing accepts them.
+func configFlags(fs *flag.FlagSet, conf storage.Config, cfg storage.AzuriteConfig) {
+	fs.String("config", "", "config file (default $"+storage.AzuriteConfigEnv+" or "+storage.AzuriteConfigPath()+")")
+	fs.String("instance", conf.Active, "Azurite instance from the config file")
+	fs.String("runtime", cfg.Runtime, "docker, podman or azurite (a local npm install); default: the first found on PATH")
+}
+
+// printExplorerFlags lists the explorer's flags with the defaults from the
+// config file.
+func printExplorerFlags(w io.Writer) {
+	conf, cfg, err := loadInstance(nil)
+	if err != nil {
+		conf = storage.Config{Active: storage.DefaultInstance}
+		cfg = storage.DefaultAzuriteConfig()
+	}
+	fs := flag.NewFlagSet("azstorecli", flag.ContinueOnError)
+	configFlags(fs, conf, cfg)
+	launchFlags(fs, &cfg)
+	fs.Bool("leave-running", conf.LeaveRunning, "leave the Azurite instances the explorer started running on quit")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// flagValue finds the value of the flag called name in args before they are
+// parsed.
+func flagValue(args []string, name string) string {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		n, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || n != name {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// launchFlags registers the Azurite launch options on fs, defaulting to and
+// writing into cfg.
+func launchFlags(fs *flag.FlagSet, cfg *storage.AzuriteConfig) {
+	fs.StringVar(&cfg.Image, "image", cfg.Image, "Azurite image")
+	fs.StringVar(&cfg.Tag, "tag", cfg.Tag, "Azurite image tag")
+	fs.StringVar(&cfg.ContainerName, "container-name", cfg.ContainerName, "name of the Azurite container")
+	fs.IntVar(&cfg.BlobPort, "blob-port", cfg.BlobPort, "host port of the blob service")
+	fs.IntVar(&cfg.QueuePort, "queue-port", cfg.QueuePort, "host port of the queue service")
+	fs.IntVar(&cfg.TablePort, "table-port", cfg.TablePort, "host port of the table service")
+	fs.StringVar(&cfg.Volume, "volume", cfg.Volume, "Docker volume or host directory mounted at /data")
+	fs.BoolVar(&cfg.Loose, "loose", cfg.Loose, "ignore unsupported headers and parameters (--loose)")
+	fs.BoolVar(&cfg.SkipAPIVersionCheck, "skip-api-version-check", cfg.SkipAPIVersionCheck, "accept any API version (--skipApiVersionCheck)")
+	fs.BoolVar(&cfg.DisableProductStyleURL, "disable-product-style-url", cfg.DisableProductStyleURL, "never read the account from the host name (--disableProductStyleUrl)")
+	fs.StringVar(&cfg.DebugLog, "debug-log", cfg.DebugLog, "host file for the Azurite debug log")
+	fs.BoolVar(&cfg.InMemory, "in-memory", cfg.InMemory, "keep data in memory instead of the volume (--inMemoryPersistence)")
+}
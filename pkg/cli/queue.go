@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+var queueCommands = map[string]command{
+	"peek": {
+		args:   "[queue]",
+		short:  "list queues, or show the front messages of a queue without dequeuing them",
+		run:    queuePeek,
+		output: true,
+	},
+	"send": {
+		args:  "<queue> <text|->",
+		short: "add a message; - reads the text from stdin",
+		run:   queueSend,
+		flags: func(fs *flag.FlagSet) {
+			fs.String("encoding", "base64", "message encoding: base64 or text")
+			fs.Duration("visibility", 0, "delay before the message becomes visible")
+			fs.Duration("ttl", 0, "time to live; 0 for the service default, negative for never")
+		},
+	},
+	"clear": {
+		args:  "<queue>",
+		short: "delete every message in a queue",
+		run:   queueClear,
+	},
+}
+
+func queuePeek(c *env, args []string) error {
+	if err := want(args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		names, err := c.client.ListQueues()
+		if err != nil {
+			return err
+		}
+		return c.write(output.Names("name", names))
+	}
+	msgs, err := c.client.PeekMessages(args[0])
+	if err != nil {
+		return err
+	}
+	return c.write(output.Messages(msgs))
+}
+
+func queueSend(c *env, args []string) error {
+	if err := want(args, 2, 2); err != nil {
+		return err
+	}
+	enc, err := storage.ParseMessageEncoding(c.flag("encoding"))
+	if err != nil {
+		return err
+	}
+	text := args[1]
+	if text == "-" {
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		text = strings.TrimSuffix(string(data), "\n")
+	}
+	return c.client.PutMessage(args[0], storage.EncodeMessage(text, enc), c.durationFlag("visibility"), c.durationFlag("ttl"))
+}
+
+func queueClear(c *env, args []string) error {
+	if err := want(args, 1, 1); err != nil {
+		return err
+	}
+	return c.client.ClearMessages(args[0])
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+var shareCommands = map[string]command{
+	"ls": {
+		args:   "[share[/dir]]",
+		short:  "list shares, or the files and directories in a share",
+		run:    shareList,
+		output: true,
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("recursive", false, "list files in every subdirectory")
+		},
+	},
+}
+
+func shareList(c *env, args []string) error {
+	if err := want(args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		names, err := c.client.ListShares()
+		if err != nil {
+			return err
+		}
+		return c.write(output.Names("name", names))
+	}
+	share, dir := splitPath(args[0])
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	var entries []storage.Entry
+	err := walk(func(dir, token string) (storage.Page, error) {
+		return c.client.ListFiles(share, dir, token)
+	}, dir, c.boolFlag("recursive"), func(e storage.Entry) {
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return err
+	}
+	return c.write(output.Entries(entries))
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+var tableCommands = map[string]command{
+	"query": {
+		args:   "[table]",
+		short:  "list tables, or the entities of a table",
+		run:    tableQuery,
+		output: true,
+		flags: func(fs *flag.FlagSet) {
+			fs.String("filter", "", "OData $filter, e.g. \"PartitionKey eq 'a'\"")
+			fs.String("select", "", "comma-separated $select columns")
+		},
+	},
+	"insert": {
+		args:  "<table> <json|->",
+		short: "write an entity, or a JSON array of them; - reads stdin",
+		run:   tableInsert,
+		flags: func(fs *flag.FlagSet) {
+			fs.String("mode", "insert", "insert, replace, merge or upsert")
+		},
+	},
+}
+
+func tableQuery(c *env, args []string) error {
+	if err := want(args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		names, err := c.client.ListTables()
+		if err != nil {
+			return err
+		}
+		return c.write(output.Names("name", names))
+	}
+	query := storage.TableQuery{Filter: c.flag("filter"), Select: c.flag("select")}
+	var entities []storage.Entity
+	token := ""
+	for {
+		page, err := c.client.QueryEntities(args[0], query, token)
+		if err != nil {
+			return err
+		}
+		for _, e := range page.Entries {
+			entities = append(entities, *e.Entity)
+		}
+		if page.Next == "" {
+			return c.write(output.Entities(entities, query.Select))
+		}
+		token = page.Next
+	}
+}
+
+func tableInsert(c *env, args []string) error {
+	if err := want(args, 2, 2); err != nil {
+		return err
+	}
+	mode, err := parseWriteMode(c.flag("mode"))
+	if err != nil {
+		return err
+	}
+	data := []byte(args[1])
+	if args[1] == "-" {
+		if data, err = io.ReadAll(c.stdin); err != nil {
+			return err
+		}
+	}
+	var entities []storage.Entity
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &entities)
+	} else {
+		var e storage.Entity
+		err = json.Unmarshal(trimmed, &e)
+		entities = append(entities, e)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid entity JSON: %w", err)
+	}
+	for _, e := range entities {
+		if err := c.client.SaveEntity(args[0], e, mode, ""); err != nil {
+			return fmt.Errorf("%s/%s: %w", e.PartitionKey, e.RowKey, err)
+		}
+	}
+	return nil
+}
+
+func parseWriteMode(s string) (storage.WriteMode, error) {
+	for _, m := range storage.WriteModes {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q, want insert, replace, merge or upsert", s)
+}
@@ -0,0 +1,229 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format selects how Write renders a Table.
+type Format string
+
+const (
+	Text  Format = "table" // aligned columns with a header line
+	JSON  Format = "json"  // one indented array of objects
+	JSONL Format = "jsonl" // one object per line
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// Formats lists every Format, for flag help.
+var Formats = []Format{Text, JSON, JSONL, CSV, YAML}
+
+// ParseFormat returns the Format called s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q, want one of %s", s, strings.Join(names, ", "))
+}
+
+// Table is tabular data with one value per column in each row. A nil value
+// is a missing field: left out of JSON and YAML objects, empty elsewhere.
+// Values are strings, numbers, bools or time.Time.
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Write renders t to w in format f.
+func Write(w io.Writer, f Format, t Table) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, t)
+	case JSONL:
+		for _, row := range t.Rows {
+			data, err := MarshalRow(t.Columns, row)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return writeCSV(w, t)
+	case YAML:
+		return writeYAML(w, t)
+	}
+	return writeText(w, t)
+}
+
+// MarshalRow encodes one row as a JSON object with keys in column order.
+func MarshalRow(columns []string, row []interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	first := true
+	for i, col := range columns {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(col)
+		value, err := json.Marshal(row[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", col, err)
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func writeJSON(w io.Writer, t Table) error {
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, row := range t.Rows {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		data, err := MarshalRow(t.Columns, row)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	}
+	b.WriteByte(']')
+	var out bytes.Buffer
+	if err := json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(w)
+	return err
+}
+
+func writeCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(t.Columns))
+		for i := range record {
+			if i < len(row) {
+				record[i] = plain(row[i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeText(w io.Writer, t Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = strings.ToUpper(col)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.Rows {
+		cells := make([]string, len(t.Columns))
+		for i := range cells {
+			if i < len(row) {
+				// Tabs and newlines would break the alignment
+				cells[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(plain(row[i]))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeYAML(w io.Writer, t Table) error {
+	var b strings.Builder
+	if len(t.Rows) == 0 {
+		b.WriteString("[]\n")
+	}
+	for _, row := range t.Rows {
+		prefix := "- "
+		wrote := false
+		for i, col := range t.Columns {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+			b.WriteString(prefix + yamlScalar(col) + ": " + yamlValue(row[i]) + "\n")
+			prefix, wrote = "  ", true
+		}
+		if !wrote {
+			b.WriteString("- {}\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// plain renders a value for CSV and text output.
+func plain(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+func yamlValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return yamlScalar(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case bool, int, int32, int64, float64, json.Number:
+		return fmt.Sprint(v)
+	}
+	return yamlScalar(fmt.Sprint(v))
+}
+
+// yamlScalar quotes s when YAML would otherwise read it as something other
+// than that plain string.
+func yamlScalar(s string) string {
+	needsQuote := s == "" || strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\n\t\\") ||
+		strings.TrimSpace(s) != s || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "?")
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		needsQuote = true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		needsQuote = true
+	}
+	if !needsQuote {
+		return s
+	}
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sample = Table{
+	Columns: []string{"name", "size", "when", "ok"},
+	Rows: [][]interface{}{
+		{"a", int64(3), time.Date(2006, 1, 2, 16, 4, 5, 0, time.FixedZone("CET", 3600)), true},
+		{"b:c", nil, nil, false},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format Format
+		table  Table
+		want   string
+	}{
+		{JSON, sample, `[
+  {
+    "name": "a",
+    "size": 3,
+    "when": "2006-01-02T16:04:05+01:00",
+    "ok": true
+  },
+  {
+    "name": "b:c",
+    "ok": false
+  }
+]
+`},
+		{JSONL, sample, `{"name":"a","size":3,"when":"2006-01-02T16:04:05+01:00","ok":true}
+{"name":"b:c","ok":false}
+`},
+		{CSV, sample, "name,size,when,ok\na,3,2006-01-02T15:04:05Z,true\nb:c,,,false\n"},
+		{Text, sample, "" +
+			"NAME  SIZE  WHEN                  OK\n" +
+			"a     3     2006-01-02T15:04:05Z  true\n" +
+			"b:c                               false\n"},
+		{YAML, sample, `- name: a
+  size: 3
+  when: 2006-01-02T15:04:05Z
+  ok: true
+- name: "b:c"
+  ok: false
+`},
+
+		{JSON, Table{Columns: []string{"x"}}, "[]\n"},
+		{JSONL, Table{Columns: []string{"x"}}, ""},
+		{CSV, Table{Columns: []string{"x"}}, "x\n"},
+		{Text, Table{Columns: []string{"x"}}, "X\n"},
+		{YAML, Table{Columns: []string{"x"}}, "[]\n"},
+
+		{YAML, Table{Columns: []string{"x"}, Rows: [][]interface{}{{nil}}}, "- {}\n"},
+		{JSON, Table{Columns: []string{"x", "y"}, Rows: [][]interface{}{{"short"}}}, "[\n  {\n    \"x\": \"short\"\n  }\n]\n"},
+		{CSV, Table{Columns: []string{"x", "y"}, Rows: [][]interface{}{{"a,b", "say \"hi\""}}}, "x,y\n\"a,b\",\"say \"\"hi\"\"\"\n"},
+		{Text, Table{Columns: []string{"x", "y"}, Rows: [][]interface{}{{"tab\there", "two\r\nlines"}}}, "X         Y\ntab here  two lines\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := Write(&b, tt.format, tt.table); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s of %v:\n got %q\nwant %q", tt.format, tt.table.Rows, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalRowError(t *testing.T) {
+	_, err := MarshalRow([]string{"ratio"}, []interface{}{math.NaN()})
+	if err == nil || !strings.HasPrefix(err.Error(), "ratio: ") {
+		t.Errorf("MarshalRow(NaN) = %v", err)
+	}
+	if err := Write(&strings.Builder{}, JSON, Table{Columns: []string{"ratio"}, Rows: [][]interface{}{{math.Inf(1)}}}); err == nil {
+		t.Error("Write(JSON) of +Inf succeeded")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"table": Text, "JSON": JSON, "jsonl": JSONL, "Csv": CSV, "yaml": YAML} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	_, err := ParseFormat("xml")
+	if want := `unknown output format "xml", want one of table, json, jsonl, csv, yaml`; err == nil || err.Error() != want {
+		t.Errorf("ParseFormat(xml) = %v, want %q", err, want)
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"two words", "two words"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"Yes", `"Yes"`},
+		{"null", `"null"`},
+		{"~", `"~"`},
+		{"12", `"12"`},
+		{"1e3", `"1e3"`},
+		{"-x", `"-x"`},
+		{"?x", `"?x"`},
+		{" lead", `" lead"`},
+		{"a#b", `"a#b"`},
+		{"key: value", `"key: value"`},
+		{"line\nnext", `"line\nnext"`},
+		{`back\slash`, `"back\\slash"`},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestYAMLValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"42", `"42"`},
+		{int64(42), "42"},
+		{2.5, "2.5"},
+		{false, "false"},
+		{time.Date(2006, 1, 2, 15, 4, 5, 5e8, time.UTC), "2006-01-02T15:04:05.5Z"},
+		{[]string{"a"}, `"[a]"`},
+	}
+	for _, tt := range tests {
+		if got := yamlValue(tt.in); got != tt.want {
+			t.Errorf("yamlValue(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
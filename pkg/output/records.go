@@ -0,0 +1,77 @@
+package output
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+// Names is a one-column table of resource names.
+func Names(column string, names []string) Table {
+	t := Table{Columns: []string{column}}
+	for _, n := range names {
+		t.Rows = append(t.Rows, []interface{}{n})
+	}
+	return t
+}
+
+// Entries is a table of listed blobs or files.
+func Entries(entries []storage.Entry) Table {
+	t := Table{Columns: []string{"path", "dir"}}
+	for _, e := range entries {
+		t.Rows = append(t.Rows, []interface{}{e.Path, e.IsDir})
+	}
+	return t
+}
+
+// Messages is a table of queue messages with their decoded bodies.
+func Messages(msgs []storage.QueueMessage) Table {
+	t := Table{Columns: []string{"id", "insertionTime", "expirationTime", "dequeueCount", "encoding", "body"}}
+	for _, m := range msgs {
+		t.Rows = append(t.Rows, []interface{}{m.ID, m.InsertionTime, m.ExpirationTime, m.DequeueCount, m.Encoding.String(), m.Body})
+	}
+	return t
+}
+
+// Entities is a table of entities: the system columns, then every property
+// seen (in sel order when a $select is given, else sorted), typed as in JSON.
+func Entities(entities []storage.Entity, sel string) Table {
+	var props []string
+	seen := map[string]bool{"PartitionKey": true, "RowKey": true, "Timestamp": true}
+	if sel != "" {
+		for _, name := range strings.Split(sel, ",") {
+			if name = strings.TrimSpace(name); name != "" && !seen[name] {
+				seen[name] = true
+				props = append(props, name)
+			}
+		}
+	} else {
+		for _, e := range entities {
+			for name := range e.Properties {
+				if !seen[name] {
+					seen[name] = true
+					props = append(props, name)
+				}
+			}
+		}
+		sort.Strings(props)
+	}
+
+	t := Table{Columns: append([]string{"PartitionKey", "RowKey", "Timestamp"}, props...)}
+	for _, e := range entities {
+		row := []interface{}{e.PartitionKey, e.RowKey, nil}
+		if !e.Timestamp.IsZero() {
+			row[2] = e.Timestamp
+		}
+		for _, name := range props {
+			if p, ok := e.Properties[name]; ok {
+				row = append(row, p.TypedValue())
+			} else {
+				row = append(row, nil)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+)
+
+func TestNamesAndEntries(t *testing.T) {
+	names := Names("container", []string{"a", "b"})
+	if want := (Table{Columns: []string{"container"}, Rows: [][]interface{}{{"a"}, {"b"}}}); !reflect.DeepEqual(names, want) {
+		t.Errorf("Names = %+v, want %+v", names, want)
+	}
+
+	entries := Entries([]storage.Entry{{Name: "d/", Path: "x/d/", IsDir: true}, {Name: "f", Path: "x/f"}})
+	want := Table{Columns: []string{"path", "dir"}, Rows: [][]interface{}{{"x/d/", true}, {"x/f", false}}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	inserted := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	m := storage.QueueMessage{ID: "m1", InsertionTime: inserted, ExpirationTime: inserted.Add(time.Hour), DequeueCount: 2, Encoding: storage.EncodingBase64, Body: "hi"}
+	got := Messages([]storage.QueueMessage{m})
+	want := Table{
+		Columns: []string{"id", "insertionTime", "expirationTime", "dequeueCount", "encoding", "body"},
+		Rows:    [][]interface{}{{"m1", inserted, inserted.Add(time.Hour), m.DequeueCount, storage.EncodingBase64.String(), "hi"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Messages = %+v, want %+v", got, want)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	ts := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	entities := []storage.Entity{
+		{PartitionKey: "p", RowKey: "1", Timestamp: ts, Properties: map[string]storage.Property{
+			"Name": {Type: storage.EdmString, Value: "ada"},
+			"Age":  {Type: storage.EdmInt32, Value: "36"},
+		}},
+		{PartitionKey: "p", RowKey: "2", Properties: map[string]storage.Property{
+			"Score": {Type: storage.EdmDouble, Value: "0.5"},
+		}},
+	}
+	tests := []struct {
+		sel  string
+		want Table
+	}{
+		{"", Table{
+			Columns: []string{"PartitionKey", "RowKey", "Timestamp", "Age", "Name", "Score"},
+			Rows: [][]interface{}{
+				{"p", "1", ts, int64(36), "ada", nil},
+				{"p", "2", nil, nil, nil, 0.5},
+			},
+		}},
+		{"Score, Name,RowKey,,Score", Table{
+			Columns: []string{"PartitionKey", "RowKey", "Timestamp", "Score", "Name"},
+			Rows: [][]interface{}{
+				{"p", "1", ts, nil, "ada"},
+				{"p", "2", nil, 0.5, nil},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		if got := Entities(entities, tt.sel); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Entities(%q) =\n%+v\nwant\n%+v", tt.sel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import "encoding/base64"
+
+// Well-known Azurite development account, see
+// https://learn.microsoft.com/azure/storage/common/storage-use-azurite#well-known-storage-account-and-key
+const (
+	devAccountName = "devstoreaccount1"
+	devAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// AuthScheme selects how requests are signed with the account key.
+type AuthScheme int
+
+const (
+	SharedKey AuthScheme = iota
+	SharedKeyLite
+)
+
+func (s AuthScheme) String() string {
+	if s == SharedKeyLite {
+		return "SharedKeyLite"
+	}
+	return "SharedKey"
+}
+
+// Account holds the credentials and service endpoints of a storage account.
+type Account struct {
+	Name          string
+	Key           []byte
+	Auth          AuthScheme
+	BlobEndpoint  string
+	QueueEndpoint string
+	TableEndpoint string
+	FileEndpoint  string // empty when the file service is not available (Azurite has none)
+}
+
+// DevelopmentAccount returns the account served by a local Azurite on its default ports.
+func DevelopmentAccount() Account {
+	key, _ := base64.StdEncoding.DecodeString(devAccountKey)
+	return Account{
+		Name:          devAccountName,
+		Key:           key,
+		BlobEndpoint:  "http://127.0.0.1:10000/" + devAccountName,
+		QueueEndpoint: "http://127.0.0.1:10001/" + devAccountName,
+		TableEndpoint: "http://127.0.0.1:10002/" + devAccountName,
+	}
+}
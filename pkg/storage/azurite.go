@@ -2,129 +2,215 @@ package storage
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
-	"strings"
-	"time"
+	"sync"
 )
 
-var currentContainerID string
+// started holds the instances this process started or restarted, by name,
+// until they are stopped. Instances that were already running are not in it.
+var (
+	startedMu sync.Mutex
+	started   = map[string]AzuriteConfig{}
+)
 
-// StartAzurite runs Azurite if not already running, using persistent storage with Docker volume.
-func StartAzurite() (<-chan string, error) {
+// StartAzurite runs Azurite as configured by cfg if not already running and
+// streams its logs until ctx is cancelled. The instance is recorded for
+// StopAzurite before StartAzurite returns.
+func StartAzurite(ctx context.Context, cfg AzuriteConfig) (<-chan string, error) {
 	logChan := make(chan string, 200)
+	if _, state, _ := AzuriteStatus(cfg); state != "running" && state != "paused" {
+		markStarted(cfg)
+	}
 
 	go func() {
 		defer close(logChan)
-		logChan <- "Starting Azurite...\n"
-
-		id := GetAzuriteContainerID()
-		if id == "" {
-			// No container exists: create with named container & volume for persistence
-			cmd := exec.Command("docker", "run", "-d",
-				"--name", "azurite-emulator",
-				"-p", "10000:10000",
-				"-p", "10001:10001",
-				"-p", "10002:10002",
-				"-v", "azurite_data:/data", // persistent Docker volume
-				"mcr.microsoft.com/azure-storage/azurite",
-			)
-			var out bytes.Buffer
-			cmd.Stdout = &out
-			cmd.Stderr = &out
-			if err := cmd.Run(); err != nil {
-				logChan <- fmt.Sprintf("Error starting Azurite: %v\n", err)
+		id, err := EnsureAzurite(cfg, func(msg string) { sendLine(ctx, logChan, msg) })
+		if err != nil {
+			sendLine(ctx, logChan, fmt.Sprintf("Error starting Azurite: %v\n", err))
+			if id == "" {
 				return
 			}
-			id = strings.TrimSpace(out.String())
-			logChan <- fmt.Sprintf("Azurite container started: %s\n", id)
-			time.Sleep(2 * time.Second)
-		} else {
-			// Container exists: start if stopped
-			statusCmd := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", id)
-			status, _ := statusCmd.Output()
-			if strings.TrimSpace(string(status)) != "true" {
-				startCmd := exec.Command("docker", "start", id)
-				startCmd.Run()
-				logChan <- fmt.Sprintf("Started existing Azurite container: %s\n", id)
-				time.Sleep(2 * time.Second)
-			}
-			logChan <- fmt.Sprintf("Using existing Azurite container: %s\n", id)
 		}
-
-		currentContainerID = id
-		streamDockerLogs(id, logChan)
+		streamLogs(ctx, cfg, logChan)
 	}()
 
 	return logChan, nil
 }
 
-// AttachLogs attaches log streaming to an existing container by ID.
-func AttachLogs(containerID string) (<-chan string, error) {
+// EnsureAzurite creates the Azurite instance described by cfg, or starts it
+// if it is stopped, waits until every service answers and returns its
+// container or process ID. An existing instance keeps the options it was
+// created with; one it starts is recorded for StopAzurite. Progress
+// messages go to logf.
+func EnsureAzurite(cfg AzuriteConfig, logf func(string)) (string, error) {
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return "", err
+	}
+	_, state, err := rt.State(cfg)
+	switch {
+	case err == nil && state == "paused":
+		if err := rt.Unpause(cfg); err != nil {
+			return "", err
+		}
+	case err == nil && state != "running":
+		if err := checkPortsFree(cfg); err != nil {
+			return "", err
+		}
+		markStarted(cfg)
+	}
+	logf(fmt.Sprintf("Starting Azurite with %s...\n", rt.Name()))
+	id, err := rt.Ensure(cfg, logf)
+	if err != nil {
+		return "", err
+	}
+
+	ready := map[string]bool{}
+	err = NewClient(cfg.Account()).WaitReady(context.Background(), ReadyTimeout, func(health []ServiceHealth) {
+		for _, h := range health {
+			if h.Ready && !ready[h.Service] {
+				logf(fmt.Sprintf("Azurite %s service ready on %s\n", h.Service, h.Address))
+			}
+			ready[h.Service] = h.Ready
+		}
+	})
+	if err != nil {
+		if rt.ID(cfg) == "" {
+			return id, fmt.Errorf("Azurite exited before it was ready; check its logs")
+		}
+		return id, fmt.Errorf("Azurite %w", err)
+	}
+	return id, nil
+}
+
+// AttachLogs streams the logs of an existing instance until ctx is cancelled.
+func AttachLogs(ctx context.Context, cfg AzuriteConfig) (<-chan string, error) {
 	logChan := make(chan string, 200)
 	go func() {
 		defer close(logChan)
-		streamDockerLogs(containerID, logChan)
+		streamLogs(ctx, cfg, logChan)
 	}()
 	return logChan, nil
 }
 
-func streamDockerLogs(containerID string, logChan chan<- string) {
-	logChan <- "Attaching to Azurite logs...\n"
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "docker", "logs", "-f", containerID)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		logChan <- fmt.Sprintf("Error getting stdout pipe: %v\n", err)
+func streamLogs(ctx context.Context, cfg AzuriteConfig, logChan chan<- string) {
+	if !sendLine(ctx, logChan, "Attaching to Azurite logs...\n") {
 		return
 	}
-	stderr, err := cmd.StderrPipe()
+	rt, err := RuntimeFor(cfg)
 	if err != nil {
-		logChan <- fmt.Sprintf("Error getting stderr pipe: %v\n", err)
-		return
-	}
-	if err := cmd.Start(); err != nil {
-		logChan <- fmt.Sprintf("Error starting log stream: %v\n", err)
+		sendLine(ctx, logChan, fmt.Sprintf("Error starting log stream: %v\n", err))
 		return
 	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	go copyToChan(stdout, logChan)
-	go copyToChan(stderr, logChan)
-	cmd.Wait()
+	r, w := io.Pipe()
+	defer r.Close()
+	go func() {
+		w.CloseWithError(rt.Logs(ctx, cfg, true, w))
+	}()
+	copyToChan(ctx, r, logChan)
 }
 
-func copyToChan(r io.Reader, ch chan<- string) {
+func copyToChan(ctx context.Context, r io.Reader, ch chan<- string) {
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		ch <- scanner.Text() + "\n"
+		if !sendLine(ctx, ch, scanner.Text()+"\n") {
+			return
+		}
 	}
-	if err := scanner.Err(); err != nil {
-		ch <- fmt.Sprintf("Error reading logs: %v\n", err)
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		sendLine(ctx, ch, fmt.Sprintf("Error reading logs: %v\n", err))
 	}
 }
 
-// GetAzuriteContainerID returns the Docker container ID for 'azurite-emulator'.
-func GetAzuriteContainerID() string {
-	cmd := exec.Command("docker", "ps", "-aq", "--filter", "name=azurite-emulator")
-	out, err := cmd.Output()
+// sendLine sends line on ch unless ctx is cancelled first, and reports
+// whether it did.
+func sendLine(ctx context.Context, ch chan<- string, line string) bool {
+	select {
+	case ch <- line:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// GetAzuriteContainerID returns the container ID of the instance, or its
+// process ID with the native runtime; "" when there is none.
+func GetAzuriteContainerID(cfg AzuriteConfig) string {
+	rt, err := RuntimeFor(cfg)
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return rt.ID(cfg)
+}
+
+func markStarted(cfg AzuriteConfig) {
+	startedMu.Lock()
+	defer startedMu.Unlock()
+	started[cfg.Name] = cfg
 }
 
-// StopAzurite stops the running Azurite container but does not remove it.
+func forgetStarted(cfg AzuriteConfig) {
+	startedMu.Lock()
+	defer startedMu.Unlock()
+	delete(started, cfg.Name)
+}
+
+// StopAzurite stops every instance this process started or restarted and
+// has not stopped since, but does not remove them. Instances that were
+// already running are left alone.
 func StopAzurite() {
-	if currentContainerID == "" {
-		return
+	startedMu.Lock()
+	instances := make([]AzuriteConfig, 0, len(started))
+	for _, cfg := range started {
+		instances = append(instances, cfg)
+	}
+	startedMu.Unlock()
+	for _, cfg := range instances {
+		StopAzuriteContainer(cfg)
+	}
+}
+
+// AzuriteStatus returns the ID and state ("running", "exited", ...) of the
+// instance; id is "" when it does not exist.
+func AzuriteStatus(cfg AzuriteConfig) (id, state string, err error) {
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return "", "", err
 	}
-	exec.Command("docker", "stop", currentContainerID).Run()
-	currentContainerID = ""
+	return rt.State(cfg)
 }
 
+// AzuriteVersion returns the image of the instance's container, or the
+// version of the local azurite binary.
+func AzuriteVersion(cfg AzuriteConfig) string {
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return ""
+	}
+	return rt.Version(cfg)
+}
 
+// StopAzuriteContainer stops the instance, whichever process started it.
+func StopAzuriteContainer(cfg AzuriteConfig) error {
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return err
+	}
+	forgetStarted(cfg)
+	return rt.Stop(cfg)
+}
+
+// WriteAzuriteLogs copies the logs of the instance to w, waiting for new
+// output when follow is set.
+func WriteAzuriteLogs(cfg AzuriteConfig, w io.Writer, follow bool) error {
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return err
+	}
+	return rt.Logs(context.Background(), cfg, follow, w)
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyToChanCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan string, 1)
+	copied := make(chan struct{})
+	go func() {
+		copyToChan(ctx, strings.NewReader("one\ntwo\nthree\n"), ch)
+		close(copied)
+	}()
+	if line := <-ch; line != "one\n" {
+		t.Errorf("first line %q", line)
+	}
+	// Nobody reads the rest; cancelling must still end the copy
+	cancel()
+	select {
+	case <-copied:
+	case <-time.After(time.Second):
+		t.Fatal("copyToChan blocked after its context was cancelled")
+	}
+}
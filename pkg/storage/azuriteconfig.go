@@ -0,0 +1,342 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AzuriteConfigEnv names a config file to use instead of the default one.
+const AzuriteConfigEnv = "AZSTORECLI_CONFIG"
+
+// DefaultInstance names the Azurite configured by the "azurite" object.
+const DefaultInstance = "default"
+
+// Ports Azurite listens on inside the container.
+const (
+	azuriteBlobPort  = 10000
+	azuriteQueuePort = 10001
+	azuriteTablePort = 10002
+)
+
+// AzuriteConfig describes how one Azurite instance is launched. The default
+// instance is read from the "azurite" object of the config file; fields left
+// out keep their defaults. Changes apply when the container is next created.
+type AzuriteConfig struct {
+	Name string `json:"-"`
+
+	// "docker", "podman" or "azurite" for a local npm install; "" picks the
+	// first found on PATH. The image settings only apply to containers.
+	Runtime string `json:"runtime"`
+
+	Image         string `json:"image"`
+	Tag           string `json:"tag"`
+	ContainerName string `json:"containerName"`
+
+	// Host ports mapped to the blob, queue and table services
+	BlobPort  int `json:"blobPort"`
+	QueuePort int `json:"queuePort"`
+	TablePort int `json:"tablePort"`
+
+	// Volume mounted at /data: a Docker volume name, or a host directory
+	// (anything containing a path separator or starting with "." or "~")
+	Volume string `json:"volume"`
+
+	Loose                  bool `json:"loose"`
+	SkipAPIVersionCheck    bool `json:"skipApiVersionCheck"`
+	DisableProductStyleURL bool `json:"disableProductStyleUrl"`
+
+	// Host file Azurite writes its debug log to; "" disables it
+	DebugLog string `json:"debugLog"`
+
+	// Keep all data in memory; Volume is not mounted
+	InMemory bool `json:"inMemoryPersistence"`
+}
+
+// Config is the contents of the config file.
+type Config struct {
+	// Default instance first, then the named ones in alphabetical order
+	Instances []AzuriteConfig
+
+	// Instance the explorer and commands use unless told otherwise
+	Active string
+
+	// Whether quitting the explorer leaves the instances it started running
+	LeaveRunning bool
+}
+
+// Instance returns the instance called name.
+func (c Config) Instance(name string) (AzuriteConfig, error) {
+	for _, inst := range c.Instances {
+		if inst.Name == name {
+			return inst, nil
+		}
+	}
+	return AzuriteConfig{}, fmt.Errorf("no Azurite instance %q in the config file", name)
+}
+
+// Replace swaps in inst for the instance of the same name.
+func (c *Config) Replace(inst AzuriteConfig) {
+	for i := range c.Instances {
+		if c.Instances[i].Name == inst.Name {
+			c.Instances[i] = inst
+		}
+	}
+}
+
+// configFile is the JSON layout of the config file. Each of Instances starts
+// from the "azurite" settings with its own container and volume names, so
+// usually only the name and ports need setting.
+type configFile struct {
+	Azurite   *AzuriteConfig             `json:"azurite"`
+	Instances map[string]json.RawMessage `json:"instances"`
+	Active    string                     `json:"active"`
+
+	LeaveRunning bool `json:"leaveRunningOnQuit"`
+}
+
+// DefaultAzuriteConfig returns the settings used without a config file.
+func DefaultAzuriteConfig() AzuriteConfig {
+	return AzuriteConfig{
+		Name:          DefaultInstance,
+		Image:         "mcr.microsoft.com/azure-storage/azurite",
+		Tag:           "latest",
+		ContainerName: "azurite-emulator",
+		BlobPort:      azuriteBlobPort,
+		QueuePort:     azuriteQueuePort,
+		TablePort:     azuriteTablePort,
+		Volume:        "azurite_data",
+	}
+}
+
+// AzuriteConfigPath returns $AZSTORECLI_CONFIG, or config.json in the
+// azstorecli directory of the user's config directory.
+func AzuriteConfigPath() string {
+	if p := os.Getenv(AzuriteConfigEnv); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "azstorecli", "config.json")
+}
+
+// LoadConfig reads the config file at path, or at AzuriteConfigPath when path
+// is "". A missing default file yields just the default instance.
+func LoadConfig(path string) (Config, error) {
+	base := DefaultAzuriteConfig()
+	conf := Config{Instances: []AzuriteConfig{base}, Active: DefaultInstance}
+	explicit := path != ""
+	if !explicit {
+		path = AzuriteConfigPath()
+		if path == "" {
+			return conf, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return conf, nil
+		}
+		return conf, err
+	}
+	file := configFile{Azurite: &base}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return conf, fmt.Errorf("%s: %w", path, err)
+	}
+	base.Name = DefaultInstance
+	conf.Instances = []AzuriteConfig{base}
+
+	names := make([]string, 0, len(file.Instances))
+	for name := range file.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == DefaultInstance || name == "" {
+			return conf, fmt.Errorf("%s: invalid instance name %q", path, name)
+		}
+		inst := base
+		inst.Name = name
+		inst.ContainerName = "azurite-" + name
+		inst.Volume = "azurite_" + name + "_data"
+		if err := json.Unmarshal(file.Instances[name], &inst); err != nil {
+			return conf, fmt.Errorf("%s: instance %s: %w", path, name, err)
+		}
+		conf.Instances = append(conf.Instances, inst)
+	}
+	if file.Active != "" {
+		conf.Active = file.Active
+	}
+	conf.LeaveRunning = file.LeaveRunning
+	if err := conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%s: %w", path, err)
+	}
+	return conf, nil
+}
+
+// Validate checks every instance, that no two share a container, volume or
+// host port, and that Active names one of them.
+func (c Config) Validate() error {
+	containers := map[string]string{}
+	volumes := map[string]string{}
+	ports := map[int]string{}
+	for _, inst := range c.Instances {
+		if err := inst.Validate(); err != nil {
+			return err
+		}
+		if other, ok := containers[inst.ContainerName]; ok {
+			return fmt.Errorf("instances %s and %s use the same container %s", other, inst.Name, inst.ContainerName)
+		}
+		containers[inst.ContainerName] = inst.Name
+		if !inst.InMemory {
+			if other, ok := volumes[inst.Volume]; ok {
+				return fmt.Errorf("instances %s and %s use the same volume %s", other, inst.Name, inst.Volume)
+			}
+			volumes[inst.Volume] = inst.Name
+		}
+		for _, p := range []int{inst.BlobPort, inst.QueuePort, inst.TablePort} {
+			if other, ok := ports[p]; ok {
+				return fmt.Errorf("instances %s and %s both use port %d", other, inst.Name, p)
+			}
+			ports[p] = inst.Name
+		}
+	}
+	_, err := c.Instance(c.Active)
+	return err
+}
+
+// Validate checks the settings that docker would otherwise reject late.
+func (c AzuriteConfig) Validate() error {
+	if c.Runtime != "" {
+		if _, err := runtimeNamed(c.Runtime); err != nil {
+			return fmt.Errorf("azurite %s: %w", c.Name, err)
+		}
+	}
+	if c.Image == "" || c.ContainerName == "" {
+		return fmt.Errorf("azurite %s: image and container name are required", c.Name)
+	}
+	for _, p := range []int{c.BlobPort, c.QueuePort, c.TablePort} {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("azurite %s: invalid port %d", c.Name, p)
+		}
+	}
+	if !c.InMemory && c.Volume == "" {
+		return fmt.Errorf("azurite %s: a volume is required unless persistence is in memory", c.Name)
+	}
+	return nil
+}
+
+// ImageRef returns the image with its tag, e.g. "mcr.microsoft.com/azure-storage/azurite:latest".
+func (c AzuriteConfig) ImageRef() string {
+	if c.Tag == "" {
+		return c.Image
+	}
+	return c.Image + ":" + c.Tag
+}
+
+// Account returns the development account served on the configured ports.
+func (c AzuriteConfig) Account() Account {
+	account := DevelopmentAccount()
+	endpoint := func(port int) string {
+		return "http://127.0.0.1:" + strconv.Itoa(port) + "/" + account.Name
+	}
+	account.BlobEndpoint = endpoint(c.BlobPort)
+	account.QueueEndpoint = endpoint(c.QueuePort)
+	account.TableEndpoint = endpoint(c.TablePort)
+	return account
+}
+
+// DefaultAccount parses $AZURE_STORAGE_CONNECTION_STRING, or returns the
+// account of the Azurite described by c when it is unset.
+func DefaultAccount(c AzuriteConfig) (Account, error) {
+	if s := os.Getenv(ConnectionStringEnv); s != "" {
+		return ParseConnectionString(s)
+	}
+	return c.Account(), nil
+}
+
+// dockerRunArgs returns the "docker run" arguments that create the container.
+func (c AzuriteConfig) dockerRunArgs() ([]string, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	args := []string{"run", "-d", "--name", c.ContainerName,
+		"-p", fmt.Sprintf("%d:%d", c.BlobPort, azuriteBlobPort),
+		"-p", fmt.Sprintf("%d:%d", c.QueuePort, azuriteQueuePort),
+		"-p", fmt.Sprintf("%d:%d", c.TablePort, azuriteTablePort),
+	}
+	azurite := []string{"azurite",
+		"--blobHost", "0.0.0.0",
+		"--queueHost", "0.0.0.0",
+		"--tableHost", "0.0.0.0",
+	}
+
+	if c.InMemory {
+		azurite = append(azurite, "--inMemoryPersistence")
+	} else {
+		volume, err := mountSource(c.Volume)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-v", volume+":/data")
+		azurite = append(azurite, "--location", "/data")
+	}
+	if c.DebugLog != "" {
+		// Azurite writes inside the container, so the log's directory is
+		// bind-mounted and the file named relative to it.
+		path, err := hostPath(c.DebugLog)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-v", filepath.Dir(path)+":/debug")
+		azurite = append(azurite, "--debug", "/debug/"+filepath.Base(path))
+	}
+	args = append(args, c.ImageRef())
+	args = append(args, azurite...)
+	return append(args, c.serviceFlags()...), nil
+}
+
+// serviceFlags returns the azurite flags for the request handling options.
+func (c AzuriteConfig) serviceFlags() []string {
+	var flags []string
+	if c.Loose {
+		flags = append(flags, "--loose")
+	}
+	if c.SkipAPIVersionCheck {
+		flags = append(flags, "--skipApiVersionCheck")
+	}
+	if c.DisableProductStyleURL {
+		flags = append(flags, "--disableProductStyleUrl")
+	}
+	return flags
+}
+
+// mountSource returns a volume name unchanged and turns a host path into the
+// absolute path docker needs for a bind mount.
+func mountSource(volume string) (string, error) {
+	if !strings.ContainsRune(volume, filepath.Separator) && !strings.ContainsRune(volume, '/') &&
+		!strings.HasPrefix(volume, ".") && !strings.HasPrefix(volume, "~") {
+		return volume, nil
+	}
+	return hostPath(volume)
+}
+
+// hostPath expands a leading "~" and makes path absolute.
+func hostPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return filepath.Abs(path)
+}
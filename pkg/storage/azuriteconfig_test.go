@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"azurite": {"tag": "3.31.0", "loose": true},
+		"instances": {
+			"second": {"blobPort": 20000, "queuePort": 20001, "tablePort": 20002},
+			"mem": {"blobPort": 30000, "queuePort": 30001, "tablePort": 30002, "inMemoryPersistence": true, "runtime": "podman"}
+		},
+		"active": "second",
+		"leaveRunningOnQuit": true
+	}`)
+	conf, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := DefaultAzuriteConfig()
+	def.Tag, def.Loose = "3.31.0", true
+	second := def
+	second.Name, second.ContainerName, second.Volume = "second", "azurite-second", "azurite_second_data"
+	second.BlobPort, second.QueuePort, second.TablePort = 20000, 20001, 20002
+	mem := def
+	mem.Name, mem.ContainerName, mem.Volume = "mem", "azurite-mem", "azurite_mem_data"
+	mem.BlobPort, mem.QueuePort, mem.TablePort = 30000, 30001, 30002
+	mem.InMemory, mem.Runtime = true, "podman"
+
+	want := Config{Instances: []AzuriteConfig{def, mem, second}, Active: "second", LeaveRunning: true}
+	if !reflect.DeepEqual(conf, want) {
+		t.Errorf("LoadConfig =\n%+v\nwant\n%+v", conf, want)
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	t.Setenv(AzuriteConfigEnv, filepath.Join(t.TempDir(), "none.json"))
+	conf, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("missing default file: %v", err)
+	}
+	if want := (Config{Instances: []AzuriteConfig{DefaultAzuriteConfig()}, Active: DefaultInstance}); !reflect.DeepEqual(conf, want) {
+		t.Errorf("LoadConfig = %+v, want %+v", conf, want)
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "none.json")); err == nil {
+		t.Error("missing explicit file succeeded")
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{`, "unexpected end of JSON input"},
+		{`{"instances": {"default": {}}}`, `invalid instance name "default"`},
+		{`{"instances": {"b": {"blobPort": "x"}}}`, "instance b:"},
+		{`{"instances": {"b": {}}}`, "instances default and b both use port 10000"},
+		{`{"instances": {"b": {"blobPort": 1, "queuePort": 2, "tablePort": 3, "containerName": "azurite-emulator"}}}`, "use the same container azurite-emulator"},
+		{`{"instances": {"b": {"blobPort": 1, "queuePort": 2, "tablePort": 3, "volume": "azurite_data"}}}`, "use the same volume azurite_data"},
+		{`{"active": "nope"}`, `no Azurite instance "nope"`},
+		{`{"azurite": {"runtime": "lxc"}}`, `unknown runtime "lxc"`},
+		{`{"azurite": {"blobPort": 70000}}`, "invalid port 70000"},
+		{`{"azurite": {"image": ""}}`, "image and container name are required"},
+		{`{"azurite": {"volume": ""}}`, "a volume is required"},
+	}
+	for _, tt := range tests {
+		_, err := LoadConfig(writeConfig(t, tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadConfig(%s) = %v, want %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestConfigValidateSharedVolumeInMemory(t *testing.T) {
+	a, b := DefaultAzuriteConfig(), DefaultAzuriteConfig()
+	b.Name, b.ContainerName, b.BlobPort, b.QueuePort, b.TablePort, b.InMemory = "b", "azurite-b", 1, 2, 3, true
+	conf := Config{Instances: []AzuriteConfig{a, b}, Active: DefaultInstance}
+	if err := conf.Validate(); err != nil {
+		t.Errorf("an in-memory instance may reuse a volume name: %v", err)
+	}
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		edit func(*AzuriteConfig)
+		want string
+	}{
+		{
+			name: "default",
+			edit: func(*AzuriteConfig) {},
+			want: "run -d --name azurite-emulator -p 10000:10000 -p 10001:10001 -p 10002:10002 -v azurite_data:/data " +
+				"mcr.microsoft.com/azure-storage/azurite:latest azurite --blobHost 0.0.0.0 --queueHost 0.0.0.0 --tableHost 0.0.0.0 --location /data",
+		},
+		{
+			name: "in memory with flags",
+			edit: func(c *AzuriteConfig) {
+				c.InMemory, c.Loose, c.SkipAPIVersionCheck, c.DisableProductStyleURL = true, true, true, true
+				c.BlobPort, c.Tag = 20000, ""
+			},
+			want: "run -d --name azurite-emulator -p 20000:10000 -p 10001:10001 -p 10002:10002 " +
+				"mcr.microsoft.com/azure-storage/azurite azurite --blobHost 0.0.0.0 --queueHost 0.0.0.0 --tableHost 0.0.0.0 --inMemoryPersistence " +
+				"--loose --skipApiVersionCheck --disableProductStyleUrl",
+		},
+		{
+			name: "host directory and debug log",
+			edit: func(c *AzuriteConfig) {
+				c.Volume = filepath.Join(dir, "data")
+				c.DebugLog = filepath.Join(dir, "logs", "debug.log")
+			},
+			want: "run -d --name azurite-emulator -p 10000:10000 -p 10001:10001 -p 10002:10002 -v " + filepath.Join(dir, "data") + ":/data " +
+				"-v " + filepath.Join(dir, "logs") + ":/debug " +
+				"mcr.microsoft.com/azure-storage/azurite:latest azurite --blobHost 0.0.0.0 --queueHost 0.0.0.0 --tableHost 0.0.0.0 --location /data --debug /debug/debug.log",
+		},
+	}
+	for _, tt := range tests {
+		cfg := DefaultAzuriteConfig()
+		tt.edit(&cfg)
+		args, err := cfg.dockerRunArgs()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.Join(args, " "); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+
+	cfg := DefaultAzuriteConfig()
+	cfg.BlobPort = 0
+	if _, err := cfg.dockerRunArgs(); err == nil {
+		t.Error("dockerRunArgs with an invalid port succeeded")
+	}
+}
+
+func TestMountSource(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	cwd, _ := os.Getwd()
+	tests := []struct{ volume, want string }{
+		{"azurite_data", "azurite_data"},
+		{"./data", filepath.Join(cwd, "data")},
+		{"~/azurite", filepath.Join(home, "azurite")},
+		{"/srv/azurite", "/srv/azurite"},
+	}
+	for _, tt := range tests {
+		if got, err := mountSource(tt.volume); err != nil || got != tt.want {
+			t.Errorf("mountSource(%q) = %q, %v; want %q", tt.volume, got, err, tt.want)
+		}
+	}
+}
+
+func TestAzuriteAccount(t *testing.T) {
+	cfg := DefaultAzuriteConfig()
+	cfg.BlobPort, cfg.QueuePort, cfg.TablePort = 20000, 20001, 20002
+	a := cfg.Account()
+	if a.BlobEndpoint != "http://127.0.0.1:20000/devstoreaccount1" || a.QueueEndpoint != "http://127.0.0.1:20001/devstoreaccount1" ||
+		a.TableEndpoint != "http://127.0.0.1:20002/devstoreaccount1" || a.FileEndpoint != "" {
+		t.Errorf("Account = %+v", a)
+	}
+
+	t.Setenv(ConnectionStringEnv, "")
+	if got, err := DefaultAccount(cfg); err != nil || !reflect.DeepEqual(got, a) {
+		t.Errorf("DefaultAccount without a connection string = %+v, %v", got, err)
+	}
+	t.Setenv(ConnectionStringEnv, "UseDevelopmentStorage=true")
+	if got, err := DefaultAccount(cfg); err != nil || got.BlobEndpoint != "http://127.0.0.1:10000/devstoreaccount1" {
+		t.Errorf("DefaultAccount with a connection string = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ResourceKind identifies one of the four storage services shown in the explorer.
+type ResourceKind int
+
+const (
+	Containers ResourceKind = iota
+	Queues
+	Shares
+	Tables
+)
+
+// Entry is a single item listed inside a container, queue, share or table.
+type Entry struct {
+	Name  string // display name, relative to the listed prefix
+	Path  string // full blob name, file path, message ID or PartitionKey/RowKey
+	IsDir bool   // virtual directory (blob prefix) that can be descended into
+
+	Message *QueueMessage // set for queue listings
+	Entity  *Entity       // set for table listings
+}
+
+// PageSize is how many items a single ListChildren call asks the service for.
+const PageSize = 200
+
+// Page is one batch of a listing.
+type Page struct {
+	Entries []Entry
+	Next    string // continuation token for the following page; "" when done
+}
+
+// Backend lists what a storage account actually holds.
+type Backend interface {
+	ListContainers() ([]string, error)
+	ListQueues() ([]string, error)
+	ListShares() ([]string, error)
+	ListTables() ([]string, error)
+	// ListChildren returns one page of the blobs, messages, files or entities of
+	// the named resource, starting at token ("" for the first page). For
+	// containers and shares, prefix selects a directory ("" for the root).
+	ListChildren(kind ResourceKind, name, prefix, token string) (Page, error)
+
+	// WaitReady probes the blob, queue and table services until they answer;
+	// see Client.WaitReady.
+	WaitReady(ctx context.Context, timeout time.Duration, report func([]ServiceHealth)) error
+
+	// Creating and deleting resources; see ValidateName for the naming rules.
+	CreateContainer(name string, access PublicAccess) error
+	DeleteContainer(name string) error
+	CreateQueue(name string, metadata map[string]string) error
+	DeleteQueue(name string) error
+	CreateShare(name string, quota int) error
+	DeleteShare(name string) error
+	CreateTable(name string) error
+	DeleteTable(name string) error
+
+	// UploadFile copies a local file into a block blob.
+	UploadFile(container, blob, path string, opts UploadOptions, progress Progress) error
+	// DownloadFile copies a blob to a local file, resuming a previous partial download.
+	DownloadFile(container, blob, path string, progress Progress) error
+	// ReadBlob returns the first limit bytes of a blob for previewing.
+	ReadBlob(container, blob string, limit int64) ([]byte, BlobProperties, error)
+	DeleteBlob(container, blob string) error
+	GetBlobProperties(container, blob string) (BlobProperties, error)
+	SetBlobMetadata(container, blob string, metadata map[string]string, etag string) error
+	SetBlobHTTPHeaders(container, blob string, headers BlobHTTPHeaders, etag string) error
+
+	PutMessage(queue, text string, visibility, ttl time.Duration) error
+	DequeueMessage(queue string) (QueueMessage, error)
+	// UpdateMessage and DeleteMessage only act on the message at the front of the queue.
+	UpdateMessage(queue, id, text string) error
+	DeleteMessage(queue, id string) error
+	ClearMessages(queue string) error
+
+	// QueryEntities lists a table like ListChildren, narrowed by an OData query.
+	QueryEntities(table string, query TableQuery, token string) (Page, error)
+	SaveEntity(table string, e Entity, mode WriteMode, etag string) error
+	DeleteEntity(table, pk, rk, etag string) error
+	SubmitBatch(table string, ops []BatchOperation) ([]BatchResult, error)
+
+	// Paths in a share are relative to its root; directories end in "/".
+	CreateDirectory(share, dir string) error
+	DeleteDirectory(share, dir string) error
+	DeleteFile(share, path string) error
+	UploadShareFile(share, name, path string, progress Progress) error
+	DownloadShareFile(share, name, path string, progress Progress) error
+	GetFileProperties(share, path string) (FileProperties, error)
+}
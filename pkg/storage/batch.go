@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxBatchOperations is the service limit for one entity group transaction.
+const maxBatchOperations = 100
+
+// BatchOperation is one change in an entity group transaction.
+type BatchOperation struct {
+	Delete bool      // delete the entity instead of writing it
+	Mode   WriteMode // how to write it when Delete is false
+	Entity Entity    // for deletes only the keys are used
+	ETag   string    // If-Match for Replace, Merge and Delete; empty means "*"
+}
+
+func (op BatchOperation) String() string {
+	verb := op.Mode.String()
+	if op.Delete {
+		verb = "delete"
+	}
+	return fmt.Sprintf("%s %s/%s", verb, op.Entity.PartitionKey, op.Entity.RowKey)
+}
+
+// BatchResult is the outcome of one operation of a submitted batch.
+// StatusCode is 0 for operations that were rolled back because another one failed.
+type BatchResult struct {
+	StatusCode int
+	Err        error
+}
+
+// SubmitBatch sends ops as a single $batch change set. The service applies
+// all of them or none; the results line up with ops.
+func (c *Client) SubmitBatch(table string, ops []BatchOperation) ([]BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("batch is empty")
+	}
+	if len(ops) > maxBatchOperations {
+		return nil, fmt.Errorf("batch has %d operations, the limit is %d", len(ops), maxBatchOperations)
+	}
+	for _, op := range ops[1:] {
+		if op.Entity.PartitionKey != ops[0].Entity.PartitionKey {
+			return nil, fmt.Errorf("all batch operations must share PartitionKey %q", ops[0].Entity.PartitionKey)
+		}
+	}
+
+	batchBoundary, changesetBoundary := "batch_"+randomID(), "changeset_"+randomID()
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "--%s\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n", batchBoundary, changesetBoundary)
+	base := strings.TrimRight(c.endpoint(serviceTable), "/")
+	for i, op := range ops {
+		method, path, payload, err := batchRequest(table, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op, err)
+		}
+		fmt.Fprintf(&body, "--%s\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n", changesetBoundary)
+		fmt.Fprintf(&body, "%s %s%s HTTP/1.1\r\n", method, base, path)
+		fmt.Fprintf(&body, "Content-ID: %d\r\nAccept: application/json;odata=nometadata\r\nDataServiceVersion: 3.0;\r\n", i)
+		if op.Delete || op.Mode == Replace || op.Mode == Merge {
+			etag := op.ETag
+			if etag == "" {
+				etag = "*"
+			}
+			fmt.Fprintf(&body, "If-Match: %s\r\n", etag)
+		}
+		if payload != nil {
+			fmt.Fprintf(&body, "Content-Type: application/json\r\nContent-Length: %d\r\n", len(payload))
+			if op.Mode == Insert {
+				body.WriteString("Prefer: return-no-content\r\n")
+			}
+			body.WriteString("\r\n")
+			body.Write(payload)
+		}
+		body.WriteString("\r\n")
+	}
+	fmt.Fprintf(&body, "--%s--\r\n--%s--\r\n", changesetBoundary, batchBoundary)
+
+	req, err := c.newRequest(serviceTable, http.MethodPost, "/$batch", nil, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "multipart/mixed; boundary="+batchBoundary)
+	resp, err := c.do(serviceTable, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responses, err := readBatchResponses(resp)
+	if err != nil {
+		return nil, err
+	}
+	return batchResults(len(ops), responses), nil
+}
+
+// batchRequest returns the method, path and JSON body of one operation.
+func batchRequest(table string, op BatchOperation) (string, string, []byte, error) {
+	path := entityPath(table, op.Entity.PartitionKey, op.Entity.RowKey)
+	if op.Delete {
+		return http.MethodDelete, path, nil, nil
+	}
+	payload, err := json.Marshal(op.Entity)
+	if err != nil {
+		return "", "", nil, err
+	}
+	switch op.Mode {
+	case Insert:
+		return http.MethodPost, "/" + url.PathEscape(table), payload, nil
+	case Merge:
+		return "MERGE", path, payload, nil
+	}
+	return http.MethodPut, path, payload, nil
+}
+
+// readBatchResponses unpacks the HTTP responses nested in a multipart $batch reply.
+func readBatchResponses(resp *http.Response) ([]*http.Response, error) {
+	var out []*http.Response
+	var walk func(r io.Reader, contentType string) error
+	walk = func(r io.Reader, contentType string) error {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			inner, err := http.ReadResponse(bufio.NewReader(r), nil)
+			if err != nil {
+				return err
+			}
+			data, _ := io.ReadAll(inner.Body)
+			inner.Body = io.NopCloser(bytes.NewReader(data))
+			out = append(out, inner)
+			return nil
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := walk(part, partType(part.Header)); err != nil {
+				return err
+			}
+		}
+	}
+	return out, walk(resp.Body, resp.Header.Get("Content-Type"))
+}
+
+func partType(h textproto.MIMEHeader) string {
+	if t := h.Get("Content-Type"); t != "" {
+		return t
+	}
+	return "application/http"
+}
+
+// batchResults maps the sub-responses onto n operations. A failed change set
+// returns a single response whose error message starts with the index of the
+// failing operation ("2:The specified resource does not exist.").
+func batchResults(n int, responses []*http.Response) []BatchResult {
+	results := make([]BatchResult, n)
+	if len(responses) == n {
+		for i, r := range responses {
+			results[i].StatusCode = r.StatusCode
+			if r.StatusCode >= 300 {
+				results[i].Err = parseResponseError(r)
+			}
+		}
+		return results
+	}
+	for i := range results {
+		results[i].Err = fmt.Errorf("rolled back")
+	}
+	if len(responses) == 0 {
+		return results
+	}
+	r := responses[0]
+	failed := 0
+	rerr := parseResponseError(r).(*ResponseError)
+	if idx, msg, ok := strings.Cut(rerr.Message, ":"); ok {
+		if i, err := strconv.Atoi(idx); err == nil && i >= 0 && i < n {
+			failed, rerr.Message = i, msg
+		}
+	}
+	results[failed] = BatchResult{StatusCode: r.StatusCode, Err: rerr}
+	return results
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSubmitBatchValidation(t *testing.T) {
+	c := NewClient(DevelopmentAccount())
+	tooMany := make([]BatchOperation, maxBatchOperations+1)
+	mixed := []BatchOperation{{Entity: Entity{PartitionKey: "a"}}, {Entity: Entity{PartitionKey: "b"}}}
+	bad := []BatchOperation{{Entity: Entity{PartitionKey: "a", Properties: map[string]Property{"N": {EdmInt32, "x"}}}}}
+	tests := []struct {
+		ops  []BatchOperation
+		want string
+	}{
+		{nil, "batch is empty"},
+		{tooMany, "batch has 101 operations, the limit is 100"},
+		{mixed, `all batch operations must share PartitionKey "a"`},
+		{bad, "operation 0 (insert a/): "},
+	}
+	for _, tt := range tests {
+		_, err := c.SubmitBatch("people", tt.ops)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("SubmitBatch = %v, want %q", err, tt.want)
+		}
+	}
+}
+
+// batchPart is one operation of a $batch request as the service reads it.
+type batchPart struct {
+	method, url string
+	header      http.Header
+	body        string
+}
+
+// parseBatch reads the change set of a $batch request body.
+func parseBatch(contentType string, body io.Reader) ([]batchPart, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	batch := multipart.NewReader(body, params["boundary"])
+	changeset, err := batch.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(changeset.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		return nil, fmt.Errorf("change set Content-Type %q: %v", changeset.Header.Get("Content-Type"), err)
+	}
+	var parts []batchPart
+	mr := multipart.NewReader(changeset, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if got := part.Header.Get("Content-Type"); got != "application/http" {
+			return nil, fmt.Errorf("part Content-Type = %q", got)
+		}
+		data, _ := io.ReadAll(part)
+		// A part without a body ends with its last header line, as in the
+		// service's own examples; http.ReadRequest wants the blank line too
+		if !strings.Contains(string(data), "\r\n\r\n") {
+			data = append(data, "\r\n"...)
+		}
+		req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(string(data))))
+		if err != nil {
+			return nil, err
+		}
+		payload, _ := io.ReadAll(req.Body)
+		parts = append(parts, batchPart{req.Method, req.URL.Path, req.Header, string(payload)})
+	}
+	if _, err := batch.NextPart(); err != io.EOF {
+		return nil, fmt.Errorf("data after the change set: %v", err)
+	}
+	return parts, nil
+}
+
+// batchReply wraps HTTP responses in a $batch reply body.
+func batchReply(responses ...string) string {
+	var b strings.Builder
+	b.WriteString("--batchresponse_1\r\nContent-Type: multipart/mixed; boundary=changesetresponse_1\r\n\r\n")
+	for _, r := range responses {
+		b.WriteString("--changesetresponse_1\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+		b.WriteString(r + "\r\n")
+	}
+	b.WriteString("--changesetresponse_1--\r\n--batchresponse_1--\r\n")
+	return b.String()
+}
+
+func TestSubmitBatchBody(t *testing.T) {
+	var parts []batchPart
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		var err error
+		if parts, err = parseBatch(r.Header.Get("Content-Type"), strings.NewReader(string(body))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresponse_1")
+		w.WriteHeader(http.StatusAccepted)
+		var responses []string
+		for i := range parts {
+			responses = append(responses, fmt.Sprintf("HTTP/1.1 204 No Content\r\nContent-ID: %d\r\n\r\n", i))
+		}
+		fmt.Fprint(w, batchReply(responses...))
+	})
+	e := Entity{PartitionKey: "p", RowKey: "1", Properties: map[string]Property{"N": {EdmInt32, "1"}}}
+	ops := []BatchOperation{
+		{Mode: Insert, Entity: e},
+		{Mode: Merge, Entity: e, ETag: `W/"3"`},
+		{Mode: Upsert, Entity: e},
+		{Delete: true, Entity: Entity{PartitionKey: "p", RowKey: "2"}},
+	}
+	results, err := c.SubmitBatch("people", ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.requests[0].Method + " " + fake.requests[0].URL; got != "POST /devstoreaccount1/$batch" {
+		t.Errorf("request %s", got)
+	}
+
+	want := []struct {
+		method, url, ifMatch, prefer, body string
+	}{
+		{"POST", "/devstoreaccount1/people", "", "return-no-content", `{"N":1,"PartitionKey":"p","RowKey":"1"}`},
+		{"MERGE", "/devstoreaccount1/people(PartitionKey='p',RowKey='1')", `W/"3"`, "", `{"N":1,"PartitionKey":"p","RowKey":"1"}`},
+		{"PUT", "/devstoreaccount1/people(PartitionKey='p',RowKey='1')", "", "", `{"N":1,"PartitionKey":"p","RowKey":"1"}`},
+		{"DELETE", "/devstoreaccount1/people(PartitionKey='p',RowKey='2')", "*", "", ""},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("%d parts, want %d", len(parts), len(want))
+	}
+	for i, w := range want {
+		p := parts[i]
+		if p.method != w.method || p.url != w.url || p.body != w.body {
+			t.Errorf("part %d = %s %s %s, want %s %s %s", i, p.method, p.url, p.body, w.method, w.url, w.body)
+		}
+		if got := p.header.Get("If-Match"); got != w.ifMatch {
+			t.Errorf("part %d If-Match = %q, want %q", i, got, w.ifMatch)
+		}
+		if got := p.header.Get("Prefer"); got != w.prefer {
+			t.Errorf("part %d Prefer = %q, want %q", i, got, w.prefer)
+		}
+		if got := p.header.Get("Content-ID"); got != fmt.Sprint(i) {
+			t.Errorf("part %d Content-ID = %q", i, got)
+		}
+	}
+	for i, r := range results {
+		if r.StatusCode != http.StatusNoContent || r.Err != nil {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
+
+func TestSubmitBatchFailure(t *testing.T) {
+	c, _ := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresponse_1")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, batchReply("HTTP/1.1 404 Not Found\r\nContent-Type: application/json\r\n\r\n"+
+			`{"odata.error":{"code":"ResourceNotFound","message":{"lang":"en-US","value":"1:The specified resource does not exist.\nRequestId:x"}}}`))
+	})
+	ops := []BatchOperation{
+		{Mode: Upsert, Entity: Entity{PartitionKey: "p", RowKey: "1"}},
+		{Delete: true, Entity: Entity{PartitionKey: "p", RowKey: "2"}},
+		{Mode: Upsert, Entity: Entity{PartitionKey: "p", RowKey: "3"}},
+	}
+	results, err := c.SubmitBatch("people", ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("%d results", len(results))
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].StatusCode != 0 || results[i].Err == nil || results[i].Err.Error() != "rolled back" {
+			t.Errorf("result %d = %+v, want rolled back", i, results[i])
+		}
+	}
+	rerr, ok := results[1].Err.(*ResponseError)
+	if results[1].StatusCode != http.StatusNotFound || !ok || rerr.Code != "ResourceNotFound" || rerr.Message != "The specified resource does not exist." {
+		t.Errorf("result 1 = %d %v", results[1].StatusCode, results[1].Err)
+	}
+}
+
+func TestBatchResultsUnknownIndex(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"odata.error":{"code":"InvalidInput","message":{"value":"7:Bad request."}}}`)),
+	}
+	results := batchResults(2, []*http.Response{resp})
+	rerr, ok := results[0].Err.(*ResponseError)
+	if !ok || results[0].StatusCode != http.StatusBadRequest || rerr.Message != "7:Bad request." {
+		t.Errorf("result 0 = %d %v, want the whole message on the first operation", results[0].StatusCode, results[0].Err)
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "rolled back" {
+		t.Errorf("result 1 = %+v", results[1])
+	}
+}
+
+func TestBatchOperationString(t *testing.T) {
+	e := Entity{PartitionKey: "p", RowKey: "r"}
+	tests := []struct {
+		op   BatchOperation
+		want string
+	}{
+		{BatchOperation{Entity: e}, "insert p/r"},
+		{BatchOperation{Mode: Merge, Entity: e}, "merge p/r"},
+		{BatchOperation{Mode: Merge, Delete: true, Entity: e}, "delete p/r"},
+	}
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
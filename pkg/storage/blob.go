@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type containerList struct {
+	Containers []struct {
+		Name string `xml:"Name"`
+	} `xml:"Containers>Container"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type blobList struct {
+	Blobs []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>Blob"`
+	Prefixes []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>BlobPrefix"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// BlobDelimiter separates virtual directories in blob names.
+const BlobDelimiter = "/"
+
+// ListContainers implements Backend.
+func (c *Client) ListContainers() ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		q := url.Values{"comp": {"list"}}
+		if marker != "" {
+			q.Set("marker", marker)
+		}
+		var res containerList
+		if err := c.getXML(serviceBlob, "/", q, &res); err != nil {
+			return nil, err
+		}
+		for _, ct := range res.Containers {
+			names = append(names, ct.Name)
+		}
+		if res.NextMarker == "" {
+			return names, nil
+		}
+		marker = res.NextMarker
+	}
+}
+
+// ListBlobs returns one page of a single level of a container below prefix:
+// the virtual directories of the page first, then its blobs. marker is the
+// NextMarker of the previous page.
+func (c *Client) ListBlobs(container, prefix, marker string) (Page, error) {
+	q := url.Values{
+		"restype":    {"container"},
+		"comp":       {"list"},
+		"delimiter":  {BlobDelimiter},
+		"maxresults": {strconv.Itoa(PageSize)},
+	}
+	if prefix != "" {
+		q.Set("prefix", prefix)
+	}
+	if marker != "" {
+		q.Set("marker", marker)
+	}
+	var res blobList
+	if err := c.getXML(serviceBlob, "/"+url.PathEscape(container), q, &res); err != nil {
+		return Page{}, err
+	}
+	var page Page
+	for _, p := range res.Prefixes {
+		page.Entries = append(page.Entries, Entry{Name: strings.TrimPrefix(p.Name, prefix), Path: p.Name, IsDir: true})
+	}
+	for _, b := range res.Blobs {
+		page.Entries = append(page.Entries, Entry{Name: strings.TrimPrefix(b.Name, prefix), Path: b.Name})
+	}
+	page.Next = res.NextMarker
+	return page, nil
+}
+
+// ParentPrefix returns the virtual directory above prefix ("a/b/" -> "a/", "a/" -> "").
+func ParentPrefix(prefix string) string {
+	p := strings.TrimSuffix(prefix, BlobDelimiter)
+	if i := strings.LastIndex(p, BlobDelimiter); i >= 0 {
+		return p[:i+1]
+	}
+	return ""
+}
+
+// DeleteBlob deletes a blob along with its snapshots.
+func (c *Client) DeleteBlob(container, blob string) error {
+	req, err := c.newRequest(serviceBlob, http.MethodDelete, blobPath(container, blob), nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-delete-snapshots", "include")
+	return c.send(serviceBlob, req)
+}
+
+// blobPath returns the escaped request path of a blob.
+func blobPath(container, blob string) string {
+	return "/" + url.PathEscape(container) + "/" + escapePath(blob)
+}
+
+// escapePath escapes each "/"-separated segment of a blob or file name.
+func escapePath(name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// PublicAccess is the anonymous read access level of a container.
+type PublicAccess int
+
+const (
+	AccessPrivate   PublicAccess = iota // no anonymous access
+	AccessBlob                          // anonymous reads of blobs
+	AccessContainer                     // anonymous reads and listing
+)
+
+// PublicAccessLevels lists every PublicAccess in display order.
+var PublicAccessLevels = []PublicAccess{AccessPrivate, AccessBlob, AccessContainer}
+
+func (a PublicAccess) String() string {
+	switch a {
+	case AccessBlob:
+		return "blob"
+	case AccessContainer:
+		return "container"
+	}
+	return "private"
+}
+
+// CreateContainer creates a container with the given public access level.
+func (c *Client) CreateContainer(name string, access PublicAccess) error {
+	req, err := c.newRequest(serviceBlob, http.MethodPut, "/"+url.PathEscape(name), url.Values{"restype": {"container"}}, nil)
+	if err != nil {
+		return err
+	}
+	if access != AccessPrivate {
+		req.Header.Set("x-ms-blob-public-access", access.String())
+	}
+	return c.send(serviceBlob, req)
+}
+
+// DeleteContainer deletes a container and every blob in it.
+func (c *Client) DeleteContainer(name string) error {
+	req, err := c.newRequest(serviceBlob, http.MethodDelete, "/"+url.PathEscape(name), url.Values{"restype": {"container"}}, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceBlob, req)
+}
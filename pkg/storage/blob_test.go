@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestListContainersFollowsMarker(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.URL.Query().Get("marker") == "" {
+			fmt.Fprint(w, `<EnumerationResults><Containers><Container><Name>a</Name></Container>`+
+				`<Container><Name>b</Name></Container></Containers><NextMarker>/devstoreaccount1/c</NextMarker></EnumerationResults>`)
+			return
+		}
+		fmt.Fprint(w, `<EnumerationResults><Containers><Container><Name>c</Name></Container></Containers><NextMarker/></EnumerationResults>`)
+	})
+	names, err := c.ListContainers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListContainers = %v, want %v", names, want)
+	}
+	want := []string{"/devstoreaccount1/?comp=list", "/devstoreaccount1/?comp=list&marker=%2Fdevstoreaccount1%2Fc"}
+	for i, r := range fake.requests {
+		if r.URL != want[i] {
+			t.Errorf("request %d = %s, want %s", i, r.URL, want[i])
+		}
+	}
+}
+
+func TestListBlobs(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		fmt.Fprint(w, `<EnumerationResults><Blobs>`+
+			`<Blob><Name>logs/app.log</Name></Blob>`+
+			`<BlobPrefix><Name>logs/2024/</Name></BlobPrefix>`+
+			`<Blob><Name>logs/b c.txt</Name></Blob>`+
+			`</Blobs><NextMarker>m2</NextMarker></EnumerationResults>`)
+	})
+	page, err := c.ListBlobs("data", "logs/", "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Page{Entries: []Entry{
+		{Name: "2024/", Path: "logs/2024/", IsDir: true},
+		{Name: "app.log", Path: "logs/app.log"},
+		{Name: "b c.txt", Path: "logs/b c.txt"},
+	}, Next: "m2"}
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("page =\n%+v\nwant\n%+v", page, want)
+	}
+	q := fake.requests[0]
+	wantURL := "/devstoreaccount1/data?comp=list&delimiter=%2F&marker=m1&maxresults=" + strconv.Itoa(PageSize) + "&prefix=logs%2F&restype=container"
+	if q.URL != wantURL {
+		t.Errorf("request %s, want %s", q.URL, wantURL)
+	}
+}
+
+func TestParentPrefix(t *testing.T) {
+	for prefix, want := range map[string]string{"": "", "a/": "", "a/b/": "a/", "a/b/c/": "a/b/", "a": ""} {
+		if got := ParentPrefix(prefix); got != want {
+			t.Errorf("ParentPrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+func TestBlobPath(t *testing.T) {
+	tests := []struct{ container, blob, want string }{
+		{"c", "b", "/c/b"},
+		{"c", "dir/sub/file.txt", "/c/dir/sub/file.txt"},
+		{"c", "a b/c?d#e", "/c/a%20b/c%3Fd%23e"},
+		{"c", "100%", "/c/100%25"},
+	}
+	for _, tt := range tests {
+		if got := blobPath(tt.container, tt.blob); got != tt.want {
+			t.Errorf("blobPath(%q, %q) = %s, want %s", tt.container, tt.blob, got, tt.want)
+		}
+	}
+}
+
+func TestContainerRequests(t *testing.T) {
+	tests := []struct {
+		access PublicAccess
+		header string
+	}{
+		{AccessPrivate, ""},
+		{AccessBlob, "blob"},
+		{AccessContainer, "container"},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, nil)
+		if err := c.CreateContainer("data", tt.access); err != nil {
+			t.Fatal(err)
+		}
+		r := fake.requests[0]
+		if got := r.Method + " " + r.URL; got != "PUT /devstoreaccount1/data?restype=container" {
+			t.Errorf("%v: request %s", tt.access, got)
+		}
+		if got := r.Header.Get("x-ms-blob-public-access"); got != tt.header {
+			t.Errorf("%v: x-ms-blob-public-access = %q, want %q", tt.access, got, tt.header)
+		}
+	}
+
+	c, fake := newFakeClient(t, nil)
+	if err := c.DeleteBlob("data", "dir/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteContainer("data"); err != nil {
+		t.Fatal(err)
+	}
+	if r := fake.requests[0]; r.Method+" "+r.URL != "DELETE /devstoreaccount1/data/dir/b" || r.Header.Get("x-ms-delete-snapshots") != "include" {
+		t.Errorf("delete blob = %s %s %v", r.Method, r.URL, r.Header)
+	}
+	if r := fake.requests[1]; r.Method+" "+r.URL != "DELETE /devstoreaccount1/data?restype=container" {
+		t.Errorf("delete container = %s %s", r.Method, r.URL)
+	}
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const apiVersion = "2021-12-02"
+
+// ErrServiceUnavailable is returned when the account has no endpoint for a service.
+var ErrServiceUnavailable = errors.New("service not available on this account")
+
+type service int
+
+const (
+	serviceBlob service = iota
+	serviceQueue
+	serviceTable
+	serviceFile
+)
+
+// ResponseError is a non-2xx reply from the storage service.
+type ResponseError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("storage: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("storage: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Client is a Backend that talks to the storage REST API directly, signing
+// every request with the account key.
+type Client struct {
+	account Account
+	http    *http.Client
+}
+
+// NewClient returns a Client for the given account.
+func NewClient(account Account) *Client {
+	return &Client{
+		account: account,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Client) endpoint(svc service) string {
+	switch svc {
+	case serviceBlob:
+		return c.account.BlobEndpoint
+	case serviceQueue:
+		return c.account.QueueEndpoint
+	case serviceTable:
+		return c.account.TableEndpoint
+	case serviceFile:
+		return c.account.FileEndpoint
+	}
+	return ""
+}
+
+// newRequest builds a request for path (already escaped) relative to the service endpoint.
+func (c *Client) newRequest(svc service, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	base := c.endpoint(svc)
+	if base == "" {
+		return nil, ErrServiceUnavailable
+	}
+	u := strings.TrimRight(base, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if svc == serviceTable {
+		req.Header.Set("Accept", "application/json;odata=nometadata")
+		req.Header.Set("DataServiceVersion", "3.0;NetFx")
+		req.Header.Set("MaxDataServiceVersion", "3.0;NetFx")
+	}
+	return req, nil
+}
+
+// do signs and sends req, turning non-2xx replies into a *ResponseError.
+// The caller must close the body of a successful response.
+func (c *Client) do(svc service, req *http.Request) (*http.Response, error) {
+	signRequest(req, svc, c.account)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, parseResponseError(resp)
+}
+
+func parseResponseError(resp *http.Response) error {
+	rerr := &ResponseError{StatusCode: resp.StatusCode, Code: resp.Header.Get("x-ms-error-code")}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var x struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	var j struct {
+		Err struct {
+			Code    string `json:"code"`
+			Message struct {
+				Value string `json:"value"`
+			} `json:"message"`
+		} `json:"odata.error"`
+	}
+	if xml.Unmarshal(data, &x) == nil && x.Code != "" {
+		rerr.Code, rerr.Message = x.Code, x.Message
+	} else if json.Unmarshal(data, &j) == nil && j.Err.Code != "" {
+		rerr.Code, rerr.Message = j.Err.Code, j.Err.Message.Value
+	}
+	if i := strings.IndexByte(rerr.Message, '\n'); i >= 0 {
+		rerr.Message = rerr.Message[:i]
+	}
+	return rerr
+}
+
+// send sends req and discards the reply body.
+func (c *Client) send(svc service, req *http.Request) error {
+	resp, err := c.do(svc, req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// getXML sends a GET and decodes the XML reply into v.
+func (c *Client) getXML(svc service, path string, query url.Values, v interface{}) error {
+	req, err := c.newRequest(svc, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(svc, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// getJSON sends a GET and decodes the JSON reply into v, returning the
+// response headers so callers can read continuation tokens.
+func (c *Client) getJSON(svc service, path string, query url.Values, v interface{}) (http.Header, error) {
+	req, err := c.newRequest(svc, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(svc, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// ListChildren implements Backend.
+func (c *Client) ListChildren(kind ResourceKind, name, prefix, token string) (Page, error) {
+	switch kind {
+	case Containers:
+		return c.ListBlobs(name, prefix, token)
+	case Queues:
+		msgs, err := c.PeekMessages(name)
+		if err != nil {
+			return Page{}, err
+		}
+		var page Page
+		for i := range msgs {
+			m := &msgs[i]
+			page.Entries = append(page.Entries, Entry{Name: m.Text, Path: m.ID, Message: m})
+		}
+		return page, nil
+	case Shares:
+		return c.ListFiles(name, prefix, token)
+	case Tables:
+		return c.QueryEntities(name, TableQuery{}, token)
+	}
+	return Page{}, fmt.Errorf("unknown resource kind %d", kind)
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordedRequest is a request as seen by fakeService.
+type recordedRequest struct {
+	Method string
+	URL    string // path and raw query
+	Header http.Header
+	Body   []byte
+}
+
+// fakeService records the requests made to it and answers each with reply,
+// or with 204 No Content when reply is nil.
+type fakeService struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	reply    func(w http.ResponseWriter, r *http.Request, body []byte)
+}
+
+func (f *fakeService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{r.Method, r.URL.RequestURI(), r.Header.Clone(), body})
+	f.mu.Unlock()
+	if f.reply == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	f.reply(w, r, body)
+}
+
+// newFakeClient returns a Client whose every service endpoint is a
+// fakeService for the development account.
+func newFakeClient(t *testing.T, reply func(w http.ResponseWriter, r *http.Request, body []byte)) (*Client, *fakeService) {
+	fake := &fakeService{reply: reply}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	account := DevelopmentAccount()
+	base := srv.URL + "/" + account.Name
+	account.BlobEndpoint, account.QueueEndpoint, account.TableEndpoint, account.FileEndpoint = base, base, base, base
+	return NewClient(account), fake
+}
+
+func TestParseResponseError(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string // x-ms-error-code
+		body      string
+		code, msg string
+	}{
+		{
+			name: "xml",
+			body: `<?xml version="1.0" encoding="utf-8"?><Error><Code>ContainerNotFound</Code>` +
+				"<Message>The specified container does not exist.\nRequestId:1\nTime:2006</Message></Error>",
+			code: "ContainerNotFound", msg: "The specified container does not exist.",
+		},
+		{
+			name: "odata json",
+			body: `{"odata.error":{"code":"TableNotFound","message":{"lang":"en-US","value":"The table specified does not exist.\nRequestId:1"}}}`,
+			code: "TableNotFound", msg: "The table specified does not exist.",
+		},
+		{name: "header only", header: "QueueNotFound", code: "QueueNotFound"},
+		{name: "unparsable body", header: "Oops", body: "<html>", code: "Oops"},
+		{name: "nothing"},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tt.body))}
+		if tt.header != "" {
+			resp.Header.Set("x-ms-error-code", tt.header)
+		}
+		var rerr *ResponseError
+		if !errors.As(parseResponseError(resp), &rerr) {
+			t.Fatalf("%s: not a *ResponseError", tt.name)
+		}
+		if rerr.StatusCode != http.StatusNotFound || rerr.Code != tt.code || rerr.Message != tt.msg {
+			t.Errorf("%s: got %+v, want code %q message %q", tt.name, *rerr, tt.code, tt.msg)
+		}
+	}
+}
+
+func TestResponseErrorString(t *testing.T) {
+	if got, want := (&ResponseError{StatusCode: 409}).Error(), "storage: 409 Conflict"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	e := &ResponseError{StatusCode: 404, Code: "BlobNotFound", Message: "gone"}
+	if got, want := e.Error(), "storage: 404 BlobNotFound: gone"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestNewRequestHeaders(t *testing.T) {
+	c := NewClient(DevelopmentAccount())
+	req, err := c.newRequest(serviceTable, http.MethodGet, "/Tables", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := req.URL.String(), "http://127.0.0.1:10002/devstoreaccount1/Tables"; got != want {
+		t.Errorf("URL = %s, want %s", got, want)
+	}
+	for name, want := range map[string]string{
+		"x-ms-version":       apiVersion,
+		"Accept":             "application/json;odata=nometadata",
+		"DataServiceVersion": "3.0;NetFx",
+	} {
+		if got := req.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if req.Header.Get("x-ms-date") == "" {
+		t.Error("x-ms-date not set")
+	}
+
+	req, err = c.newRequest(serviceBlob, http.MethodGet, "/c", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Accept") != "" || req.Header.Get("DataServiceVersion") != "" {
+		t.Errorf("blob request has table headers: %v", req.Header)
+	}
+
+	if _, err := c.newRequest(serviceFile, http.MethodGet, "/s", nil, nil); err != ErrServiceUnavailable {
+		t.Errorf("file request on Azurite: err = %v, want ErrServiceUnavailable", err)
+	}
+}
+
+func TestDoSignsAndFails(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		w.Header().Set("x-ms-error-code", "ContainerNotFound")
+		w.WriteHeader(http.StatusNotFound)
+	})
+	req, _ := c.newRequest(serviceBlob, http.MethodGet, "/missing", nil, nil)
+	_, err := c.do(serviceBlob, req)
+	var rerr *ResponseError
+	if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusNotFound || rerr.Code != "ContainerNotFound" {
+		t.Fatalf("do = %v, want a 404 ContainerNotFound", err)
+	}
+	auth := fake.requests[0].Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "SharedKey devstoreaccount1:") {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DevelopmentConnectionString selects the local Azurite account.
+const DevelopmentConnectionString = "UseDevelopmentStorage=true"
+
+// ConnectionStringEnv is the environment variable read by DefaultConnectionString
+// and DefaultAccount.
+const ConnectionStringEnv = "AZURE_STORAGE_CONNECTION_STRING"
+
+// DefaultConnectionString returns $AZURE_STORAGE_CONNECTION_STRING, or the
+// development storage connection string when it is unset.
+func DefaultConnectionString() string {
+	if s := os.Getenv(ConnectionStringEnv); s != "" {
+		return s
+	}
+	return DevelopmentConnectionString
+}
+
+// ParseConnectionString parses a storage connection string, either
+// "UseDevelopmentStorage=true[;DevelopmentStorageProxyUri=...]" or a list of
+// AccountName, AccountKey, DefaultEndpointsProtocol, EndpointSuffix and
+// Blob/Queue/Table/FileEndpoint settings.
+func ParseConnectionString(s string) (Account, error) {
+	settings := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return Account{}, fmt.Errorf("connection string: malformed setting %q", part)
+		}
+		settings[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+
+	if strings.EqualFold(settings["usedevelopmentstorage"], "true") {
+		account := DevelopmentAccount()
+		if proxy := settings["developmentstorageproxyuri"]; proxy != "" {
+			u, err := url.Parse(proxy)
+			if err != nil || u.Host == "" {
+				return Account{}, fmt.Errorf("connection string: invalid DevelopmentStorageProxyUri %q", proxy)
+			}
+			account.BlobEndpoint = withPort(u, "10000") + "/" + account.Name
+			account.QueueEndpoint = withPort(u, "10001") + "/" + account.Name
+			account.TableEndpoint = withPort(u, "10002") + "/" + account.Name
+		}
+		return account, nil
+	}
+
+	account := Account{Name: settings["accountname"]}
+	if account.Name == "" {
+		return Account{}, fmt.Errorf("connection string: AccountName is required")
+	}
+	key, err := base64.StdEncoding.DecodeString(settings["accountkey"])
+	if err != nil || len(key) == 0 {
+		return Account{}, fmt.Errorf("connection string: AccountKey must be base64")
+	}
+	account.Key = key
+
+	protocol := settings["defaultendpointsprotocol"]
+	if protocol == "" {
+		protocol = "https"
+	}
+	// With explicit endpoints and no suffix (the usual Azurite form), services
+	// that are not listed stay unavailable instead of pointing at Azure.
+	suffix := settings["endpointsuffix"]
+	explicit := settings["blobendpoint"] != "" || settings["queueendpoint"] != "" ||
+		settings["tableendpoint"] != "" || settings["fileendpoint"] != ""
+	if suffix == "" && !explicit {
+		suffix = "core.windows.net"
+	}
+	endpoint := func(setting, svc string) string {
+		if v := settings[setting]; v != "" {
+			return strings.TrimRight(v, "/")
+		}
+		if suffix == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s://%s.%s.%s", protocol, account.Name, svc, suffix)
+	}
+	account.BlobEndpoint = endpoint("blobendpoint", "blob")
+	account.QueueEndpoint = endpoint("queueendpoint", "queue")
+	account.TableEndpoint = endpoint("tableendpoint", "table")
+	account.FileEndpoint = endpoint("fileendpoint", "file")
+	return account, nil
+}
+
+// withPort returns scheme://host:port for a development storage proxy URI.
+func withPort(u *url.URL, port string) string {
+	return u.Scheme + "://" + u.Hostname() + ":" + port
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConnectionString(t *testing.T) {
+	dev := DevelopmentAccount()
+	tests := []struct {
+		name string
+		in   string
+		want Account
+	}{
+		{
+			name: "development storage",
+			in:   "UseDevelopmentStorage=true",
+			want: dev,
+		},
+		{
+			name: "development storage with proxy",
+			in:   "UseDevelopmentStorage=true;DevelopmentStorageProxyUri=http://azurite.local",
+			want: Account{
+				Name:          dev.Name,
+				Key:           dev.Key,
+				BlobEndpoint:  "http://azurite.local:10000/devstoreaccount1",
+				QueueEndpoint: "http://azurite.local:10001/devstoreaccount1",
+				TableEndpoint: "http://azurite.local:10002/devstoreaccount1",
+			},
+		},
+		{
+			name: "explicit endpoints without a suffix",
+			in: "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=" + devAccountKey +
+				";BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1/;QueueEndpoint=http://127.0.0.1:10001/devstoreaccount1;",
+			want: Account{
+				Name:          dev.Name,
+				Key:           dev.Key,
+				BlobEndpoint:  "http://127.0.0.1:10000/devstoreaccount1",
+				QueueEndpoint: "http://127.0.0.1:10001/devstoreaccount1",
+			},
+		},
+		{
+			name: "account with the default suffix",
+			in:   "AccountName=acct;AccountKey=a2V5",
+			want: Account{
+				Name:          "acct",
+				Key:           []byte("key"),
+				BlobEndpoint:  "https://acct.blob.core.windows.net",
+				QueueEndpoint: "https://acct.queue.core.windows.net",
+				TableEndpoint: "https://acct.table.core.windows.net",
+				FileEndpoint:  "https://acct.file.core.windows.net",
+			},
+		},
+		{
+			name: "EndpointSuffix with one explicit endpoint",
+			in:   " accountname = acct ; AccountKey=a2V5;DefaultEndpointsProtocol=http;EndpointSuffix=core.chinacloudapi.cn;TableEndpoint=http://tables.example/",
+			want: Account{
+				Name:          "acct",
+				Key:           []byte("key"),
+				BlobEndpoint:  "http://acct.blob.core.chinacloudapi.cn",
+				QueueEndpoint: "http://acct.queue.core.chinacloudapi.cn",
+				TableEndpoint: "http://tables.example",
+				FileEndpoint:  "http://acct.file.core.chinacloudapi.cn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConnectionString(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConnectionStringErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"setting without =", "AccountName=acct;AccountKey", "malformed setting"},
+		{"missing account name", "AccountKey=a2V5", "AccountName is required"},
+		{"missing account key", "AccountName=acct", "AccountKey must be base64"},
+		{"key not base64", "AccountName=acct;AccountKey=not base64!", "AccountKey must be base64"},
+		{"bad proxy", "UseDevelopmentStorage=true;DevelopmentStorageProxyUri=azurite", "invalid DevelopmentStorageProxyUri"},
+		// A SAS token carries no account key, which every request here needs
+		{"SAS only", "BlobEndpoint=https://acct.blob.core.windows.net;SharedAccessSignature=sv=2021-12-02&sig=abc", "AccountName is required"},
+		{"SAS with account name", "AccountName=acct;SharedAccessSignature=sv=2021-12-02&sig=abc", "AccountKey must be base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConnectionString(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCRC64Table(t *testing.T) {
+	tests := []struct {
+		data string
+		want string // base64 of the little-endian checksum, as in x-ms-content-crc64
+	}{
+		{"123456789", "iJh5CoYUi64="}, // CRC-64/NVME check value 0xae8b14860a799888
+		{"hello", "V0JSBnCFdzM="},
+	}
+	for _, tt := range tests {
+		var sum [8]byte
+		binary.LittleEndian.PutUint64(sum[:], crc64.Checksum([]byte(tt.data), crc64Table))
+		if got := base64.StdEncoding.EncodeToString(sum[:]); got != tt.want {
+			t.Errorf("crc64(%q) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+// fakeSource serves ranges of data and records which ones were asked for.
+type fakeSource struct {
+	data   []byte
+	ranges []string
+}
+
+func (f *fakeSource) read(etag string, start, end int64) ([]byte, error) {
+	f.ranges = append(f.ranges, fmt.Sprintf("%d-%d", start, end))
+	return f.data[start : end+1], nil
+}
+
+func md5Of(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// blobServer serves data as a blob with ETag "v1", recording the ranges asked for.
+func blobServer(data []byte, md5 string, ranges *[]string) func(http.ResponseWriter, *http.Request, []byte) {
+	return func(w http.ResponseWriter, r *http.Request, body []byte) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Method == http.MethodHead {
+			if md5 != "" {
+				w.Header().Set("Content-MD5", md5)
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			return
+		}
+		rng := strings.TrimPrefix(r.Header.Get("x-ms-range"), "bytes=")
+		*ranges = append(*ranges, rng)
+		var start, end int
+		fmt.Sscanf(rng, "%d-%d", &start, &end)
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start : end+1])
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), (downloadChunk+500)/10)
+	size := len(data)
+	tests := []struct {
+		name       string
+		part       []byte // existing .part file
+		partETag   string // existing .part.etag file
+		wantRanges string
+	}{
+		{name: "fresh", wantRanges: fmt.Sprintf("0-%d %d-%d", downloadChunk-1, downloadChunk, size-1)},
+		{name: "resume", part: data[:100], partETag: `"v1"`, wantRanges: fmt.Sprintf("100-%d %d-%d", downloadChunk+99, downloadChunk+100, size-1)},
+		{name: "source changed", part: []byte("stale"), partETag: `"v0"`, wantRanges: fmt.Sprintf("0-%d %d-%d", downloadChunk-1, downloadChunk, size-1)},
+	}
+	for _, tt := range tests {
+		var ranges []string
+		c, _ := newFakeClient(t, blobServer(data, md5Of(data), &ranges))
+		path := filepath.Join(t.TempDir(), "out")
+		if tt.part != nil {
+			os.WriteFile(path+".part", tt.part, 0o644)
+			os.WriteFile(path+".part.etag", []byte(tt.partETag), 0o644)
+		}
+		var last int64
+		if err := c.DownloadFile("c", "b", path, func(done, total int64) { last = done }); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := strings.Join(ranges, " "); got != tt.wantRanges {
+			t.Errorf("%s: ranges %s, want %s", tt.name, got, tt.wantRanges)
+		}
+		if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+			t.Errorf("%s: downloaded %d bytes, not the blob", tt.name, len(got))
+		}
+		if last != int64(size) {
+			t.Errorf("%s: last progress %d, want %d", tt.name, last, size)
+		}
+		for _, leftover := range []string{path + ".part", path + ".part.etag"} {
+			if _, err := os.Stat(leftover); err == nil {
+				t.Errorf("%s: %s left behind", tt.name, leftover)
+			}
+		}
+	}
+}
+
+func TestDownloadFileMD5Mismatch(t *testing.T) {
+	var ranges []string
+	c, _ := newFakeClient(t, blobServer([]byte("hello"), md5Of([]byte("world")), &ranges))
+	path := filepath.Join(t.TempDir(), "out")
+	err := c.DownloadFile("c", "b", path, nil)
+	if err == nil || !strings.Contains(err.Error(), "Content-MD5 mismatch") {
+		t.Fatalf("DownloadFile = %v, want an MD5 mismatch", err)
+	}
+	for _, p := range []string{path, path + ".part", path + ".part.etag"} {
+		if _, err := os.Stat(p); err == nil {
+			t.Errorf("%s exists after a failed check", p)
+		}
+	}
+}
+
+func TestDownloadTo(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), (downloadChunk*2+500)/10)
+	size := int64(len(data))
+	tests := []struct {
+		name       string
+		part       []byte // existing .part file
+		partETag   string // existing .part.etag file
+		wantRanges []string
+	}{
+		{
+			name:       "fresh",
+			wantRanges: []string{fmt.Sprintf("0-%d", downloadChunk-1), fmt.Sprintf("%d-%d", downloadChunk, 2*downloadChunk-1), fmt.Sprintf("%d-%d", 2*downloadChunk, size-1)},
+		},
+		{
+			name:       "resume",
+			part:       data[:downloadChunk+100],
+			partETag:   `"v1"`,
+			wantRanges: []string{fmt.Sprintf("%d-%d", downloadChunk+100, 2*downloadChunk+99), fmt.Sprintf("%d-%d", 2*downloadChunk+100, size-1)},
+		},
+		{
+			name:       "source changed",
+			part:       []byte("stale"),
+			partETag:   `"v0"`,
+			wantRanges: []string{fmt.Sprintf("0-%d", downloadChunk-1), fmt.Sprintf("%d-%d", downloadChunk, 2*downloadChunk-1), fmt.Sprintf("%d-%d", 2*downloadChunk, size-1)},
+		},
+		{
+			name:       "part longer than source",
+			part:       append(append([]byte{}, data...), "extra"...),
+			partETag:   `"v1"`,
+			wantRanges: []string{fmt.Sprintf("0-%d", downloadChunk-1), fmt.Sprintf("%d-%d", downloadChunk, 2*downloadChunk-1), fmt.Sprintf("%d-%d", 2*downloadChunk, size-1)},
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "out")
+		if tt.part != nil {
+			os.WriteFile(path+".part", tt.part, 0o644)
+			os.WriteFile(path+".part.etag", []byte(tt.partETag), 0o644)
+		}
+		src := &fakeSource{data: data}
+		var last int64
+		err := downloadTo(path, remoteFile{size: size, etag: `"v1"`, md5: md5Of(data)}, src.read, func(done, total int64) { last = done })
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(src.ranges, tt.wantRanges) {
+			t.Errorf("%s: ranges %v, want %v", tt.name, src.ranges, tt.wantRanges)
+		}
+		if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+			t.Errorf("%s: downloaded %d bytes, not the source", tt.name, len(got))
+		}
+		if last != size {
+			t.Errorf("%s: last progress %d, want %d", tt.name, last, size)
+		}
+		for _, leftover := range []string{path + ".part", path + ".part.etag"} {
+			if _, err := os.Stat(leftover); err == nil {
+				t.Errorf("%s: %s left behind", tt.name, leftover)
+			}
+		}
+	}
+}
+
+func TestDownloadToEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	src := &fakeSource{}
+	if err := downloadTo(path, remoteFile{etag: `"v1"`}, src.read, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(src.ranges) != 0 {
+		t.Errorf("ranges %v for an empty source", src.ranges)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("Stat = %v, %v", info, err)
+	}
+}
+
+func TestDownloadToMD5Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	src := &fakeSource{data: []byte("hello")}
+	err := downloadTo(path, remoteFile{size: 5, etag: `"v1"`, md5: md5Of([]byte("world"))}, src.read, nil)
+	if err == nil || !strings.Contains(err.Error(), "Content-MD5 mismatch") {
+		t.Fatalf("downloadTo = %v, want an MD5 mismatch", err)
+	}
+	for _, p := range []string{path, path + ".part", path + ".part.etag"} {
+		if _, err := os.Stat(p); err == nil {
+			t.Errorf("%s exists after a failed check", p)
+		}
+	}
+}
+
+func TestDownloadToShortRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	read := func(etag string, start, end int64) ([]byte, error) { return []byte("he"), nil }
+	err := downloadTo(path, remoteFile{size: 5, etag: `"v1"`}, read, nil)
+	if err == nil || err.Error() != "short read at offset 0: got 2 bytes, want 5" {
+		t.Errorf("downloadTo = %v", err)
+	}
+	// The partial data and its ETag stay for a resume
+	if _, err := os.Stat(path + ".part.etag"); err != nil {
+		t.Errorf("etag file: %v", err)
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		crc     string
+		status  int
+		wantErr string
+	}{
+		{name: "checked", crc: "V0JSBnCFdzM="},
+		{name: "no checksum"},
+		{name: "corrupt", crc: "iJh5CoYUi64=", wantErr: "x-ms-content-crc64 mismatch at offset 10"},
+		{name: "changed", status: http.StatusPreconditionFailed, wantErr: "blob changed during download, start again"},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+			if tt.status != 0 {
+				w.WriteHeader(tt.status)
+				return
+			}
+			if tt.crc != "" {
+				w.Header().Set("x-ms-content-crc64", tt.crc)
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("hello"))
+		})
+		data, err := c.getRange("c", "b", `"v1"`, 10, 14)
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("%s: getRange = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(data) != "hello" {
+			t.Errorf("%s: getRange = %q, %v", tt.name, data, err)
+		}
+		h := fake.requests[0].Header
+		if h.Get("x-ms-range") != "bytes=10-14" || h.Get("x-ms-range-get-content-crc64") != "true" || h.Get("If-Match") != `"v1"` {
+			t.Errorf("%s: headers %v", tt.name, h)
+		}
+	}
+}
+
+func TestReadBlob(t *testing.T) {
+	tests := []struct {
+		name       string
+		ranged     bool // answer 206 with Content-Range, or 200 with the whole blob
+		blob       string
+		wantData   string
+		wantLength int64
+	}{
+		{name: "ranged", ranged: true, blob: "head and the rest", wantData: "head", wantLength: 17},
+		{name: "range ignored", blob: "head and the rest", wantData: "head", wantLength: 17},
+		{name: "short blob", blob: "hi", wantData: "hi", wantLength: 2},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+			w.Header().Set("x-ms-blob-content-md5", "blob-md5")
+			if !tt.ranged {
+				w.Write([]byte(tt.blob))
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-3/%d", len(tt.blob)))
+			w.Header().Set("Content-MD5", "range-md5")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(tt.blob[:4]))
+		})
+		data, props, err := c.ReadBlob("c", "b", 4)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(data) != tt.wantData || props.ContentLength != tt.wantLength || props.ContentMD5 != "blob-md5" {
+			t.Errorf("%s: ReadBlob = %q, length %d, md5 %q", tt.name, data, props.ContentLength, props.ContentMD5)
+		}
+		if got := fake.requests[0].Header.Get("x-ms-range"); got != "bytes=0-3" {
+			t.Errorf("%s: x-ms-range = %q", tt.name, got)
+		}
+	}
+
+	c, fake := newFakeClient(t, nil)
+	if _, _, err := c.ReadBlob("c", "b", 0); err == nil || len(fake.requests) != 0 {
+		t.Errorf("ReadBlob with limit 0 = %v after %d requests", err, len(fake.requests))
+	}
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EdmType is the OData type of an entity property.
+type EdmType string
+
+const (
+	EdmString   EdmType = "Edm.String"
+	EdmInt32    EdmType = "Edm.Int32"
+	EdmInt64    EdmType = "Edm.Int64"
+	EdmDouble   EdmType = "Edm.Double"
+	EdmBoolean  EdmType = "Edm.Boolean"
+	EdmDateTime EdmType = "Edm.DateTime"
+	EdmGuid     EdmType = "Edm.Guid"
+	EdmBinary   EdmType = "Edm.Binary"
+)
+
+// Property is a typed entity property. Value holds the string form: decimal
+// numbers, "true"/"false", RFC 3339 times, GUIDs and base64 for binary.
+type Property struct {
+	Type  EdmType
+	Value string
+}
+
+// Entity is a table row.
+type Entity struct {
+	PartitionKey string
+	RowKey       string
+	Timestamp    time.Time
+	ETag         string
+	Properties   map[string]Property
+}
+
+// PropertyNames returns the entity's property names in sorted order.
+func (e *Entity) PropertyNames() []string {
+	names := make([]string, 0, len(e.Properties))
+	for name := range e.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseEntity converts a JSON entity returned with odata=minimalmetadata,
+// decoded with UseNumber, into an Entity. Types the service does not annotate
+// are inferred from the JSON value.
+func parseEntity(raw map[string]interface{}) Entity {
+	e := Entity{Properties: map[string]Property{}}
+	for k, v := range raw {
+		switch {
+		case k == "PartitionKey":
+			e.PartitionKey = fmt.Sprint(v)
+		case k == "RowKey":
+			e.RowKey = fmt.Sprint(v)
+		case k == "Timestamp":
+			e.Timestamp, _ = time.Parse(time.RFC3339Nano, fmt.Sprint(v))
+		case k == "odata.etag":
+			e.ETag = fmt.Sprint(v)
+		case strings.HasPrefix(k, "odata.") || strings.Contains(k, "@odata."):
+		default:
+			p := Property{Value: fmt.Sprint(v)}
+			if t, ok := raw[k+"@odata.type"].(string); ok {
+				p.Type = EdmType(t)
+			} else {
+				p.Type = inferEdmType(v)
+			}
+			e.Properties[k] = p
+		}
+	}
+	return e
+}
+
+func inferEdmType(v interface{}) EdmType {
+	switch v := v.(type) {
+	case bool:
+		return EdmBoolean
+	case json.Number:
+		if n, err := v.Int64(); err == nil && !strings.ContainsAny(v.String(), ".eE") && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return EdmInt32
+		}
+		return EdmDouble
+	}
+	return EdmString
+}
+
+// EdmTypes lists the property types an entity can hold, in editor order.
+var EdmTypes = []EdmType{EdmString, EdmInt32, EdmInt64, EdmDouble, EdmBoolean, EdmDateTime, EdmGuid, EdmBinary}
+
+// jsonValue converts a property to its JSON wire value, validating Value
+// against Type. annotate reports whether an @odata.type annotation is needed.
+func (p Property) jsonValue() (v interface{}, annotate bool, err error) {
+	switch p.Type {
+	case EdmString, "":
+		return p.Value, false, nil
+	case EdmInt32:
+		n, err := strconv.ParseInt(p.Value, 10, 32)
+		return n, false, err
+	case EdmInt64:
+		_, err := strconv.ParseInt(p.Value, 10, 64)
+		return p.Value, true, err
+	case EdmDouble:
+		f, err := strconv.ParseFloat(p.Value, 64)
+		if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			return p.Value, true, nil
+		}
+		return f, true, err
+	case EdmBoolean:
+		b, err := strconv.ParseBool(p.Value)
+		return b, false, err
+	case EdmDateTime:
+		t, err := time.Parse(time.RFC3339Nano, p.Value)
+		return t.UTC().Format(time.RFC3339Nano), true, err
+	case EdmGuid:
+		if !guidPattern.MatchString(p.Value) {
+			return nil, false, fmt.Errorf("%q is not a GUID", p.Value)
+		}
+		return p.Value, true, nil
+	case EdmBinary:
+		_, err := base64.StdEncoding.DecodeString(p.Value)
+		return p.Value, true, err
+	}
+	return nil, false, fmt.Errorf("unsupported type %s", p.Type)
+}
+
+// TypedValue returns Value as the Go type JSON would use for Type: int64,
+// float64 or bool for numbers and booleans, the string otherwise or when
+// Value does not parse.
+func (p Property) TypedValue() interface{} {
+	switch p.Type {
+	case EdmInt32, EdmInt64:
+		if n, err := strconv.ParseInt(p.Value, 10, 64); err == nil {
+			return n
+		}
+	case EdmDouble:
+		if f, err := strconv.ParseFloat(p.Value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	case EdmBoolean:
+		if b, err := strconv.ParseBool(p.Value); err == nil {
+			return b
+		}
+	}
+	return p.Value
+}
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// MarshalJSON encodes the entity in the OData JSON format, with type
+// annotations for properties whose type JSON cannot express.
+func (e Entity) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"PartitionKey": e.PartitionKey, "RowKey": e.RowKey}
+	for name, p := range e.Properties {
+		v, annotate, err := p.jsonValue()
+		if err != nil {
+			return nil, fmt.Errorf("property %s (%s): %w", name, p.Type, err)
+		}
+		m[name] = v
+		if annotate {
+			m[name+"@odata.type"] = string(p.Type)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON reads an entity in the OData JSON format, as written by
+// MarshalJSON. Unannotated properties get their type from the JSON value.
+func (e *Entity) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	*e = parseEntity(raw)
+	return nil
+}
+
+// WriteMode selects how SaveEntity treats an existing entity with the same keys.
+type WriteMode int
+
+const (
+	Insert  WriteMode = iota // fail if it exists
+	Replace                  // replace it, which must exist
+	Merge                    // update only the given properties, which must exist
+	Upsert                   // insert, or replace if it exists
+)
+
+// WriteModes lists the modes in editor order.
+var WriteModes = []WriteMode{Insert, Replace, Merge, Upsert}
+
+func (m WriteMode) String() string {
+	switch m {
+	case Replace:
+		return "replace"
+	case Merge:
+		return "merge"
+	case Upsert:
+		return "upsert"
+	}
+	return "insert"
+}
+
+func entityPath(table, pk, rk string) string {
+	quote := func(s string) string { return url.PathEscape(strings.ReplaceAll(s, "'", "''")) }
+	return "/" + url.PathEscape(table) + "(PartitionKey='" + quote(pk) + "',RowKey='" + quote(rk) + "')"
+}
+
+// SaveEntity writes e with the given mode. For Replace and Merge a non-empty
+// etag is sent as If-Match, so the call fails with 412 if the entity changed
+// since it was read; an empty etag overwrites unconditionally.
+func (c *Client) SaveEntity(table string, e Entity, mode WriteMode, etag string) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	method, path := http.MethodPut, entityPath(table, e.PartitionKey, e.RowKey)
+	switch mode {
+	case Insert:
+		method, path = http.MethodPost, "/"+url.PathEscape(table)
+	case Merge:
+		method = "MERGE"
+	}
+	req, err := c.newRequest(serviceTable, method, path, nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch mode {
+	case Insert:
+		req.Header.Set("Prefer", "return-no-content")
+	case Replace, Merge:
+		if etag == "" {
+			etag = "*"
+		}
+		req.Header.Set("If-Match", etag)
+	}
+	return c.send(serviceTable, req)
+}
+
+// DeleteEntity removes an entity; etag works as in SaveEntity.
+func (c *Client) DeleteEntity(table, pk, rk, etag string) error {
+	req, err := c.newRequest(serviceTable, http.MethodDelete, entityPath(table, pk, rk), nil, nil)
+	if err != nil {
+		return err
+	}
+	if etag == "" {
+		etag = "*"
+	}
+	req.Header.Set("If-Match", etag)
+	return c.send(serviceTable, req)
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEntityTypes(t *testing.T) {
+	var e Entity
+	err := json.Unmarshal([]byte(`{
+		"odata.etag": "W/\"1\"",
+		"PartitionKey": "p", "RowKey": "r", "Timestamp": "2006-01-02T15:04:05.5Z",
+		"Name": "ada", "Age": 36, "Big": 4294967296, "Ratio": 0.5, "Whole": 2.0, "Exp": 1e3,
+		"Active": true,
+		"Count": "9007199254740993", "Count@odata.type": "Edm.Int64",
+		"When": "2006-01-02T15:04:05Z", "When@odata.type": "Edm.DateTime"
+	}`), &e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.PartitionKey != "p" || e.RowKey != "r" || e.ETag != `W/"1"` || e.Timestamp.Nanosecond() != 5e8 {
+		t.Errorf("system properties = %q %q %q %v", e.PartitionKey, e.RowKey, e.ETag, e.Timestamp)
+	}
+	want := map[string]Property{
+		"Name":   {EdmString, "ada"},
+		"Age":    {EdmInt32, "36"},
+		"Big":    {EdmDouble, "4294967296"},
+		"Ratio":  {EdmDouble, "0.5"},
+		"Whole":  {EdmDouble, "2.0"},
+		"Exp":    {EdmDouble, "1e3"},
+		"Active": {EdmBoolean, "true"},
+		"Count":  {EdmInt64, "9007199254740993"},
+		"When":   {EdmDateTime, "2006-01-02T15:04:05Z"},
+	}
+	if !reflect.DeepEqual(e.Properties, want) {
+		t.Errorf("Properties =\n%v\nwant\n%v", e.Properties, want)
+	}
+}
+
+func TestPropertyJSONValue(t *testing.T) {
+	tests := []struct {
+		p        Property
+		want     interface{}
+		annotate bool
+		ok       bool
+	}{
+		{Property{"", "x"}, "x", false, true},
+		{Property{EdmString, "42"}, "42", false, true},
+		{Property{EdmInt32, "-7"}, int64(-7), false, true},
+		{Property{EdmInt32, "4294967296"}, nil, false, false},
+		{Property{EdmInt64, "9007199254740993"}, "9007199254740993", true, true},
+		{Property{EdmInt64, "1.5"}, nil, false, false},
+		{Property{EdmDouble, "2.5"}, 2.5, true, true},
+		{Property{EdmDouble, "NaN"}, "NaN", true, true},
+		{Property{EdmDouble, "-Inf"}, "-Inf", true, true},
+		{Property{EdmDouble, "abc"}, nil, false, false},
+		{Property{EdmBoolean, "true"}, true, false, true},
+		{Property{EdmBoolean, "maybe"}, nil, false, false},
+		{Property{EdmDateTime, "2006-01-02T16:04:05+01:00"}, "2006-01-02T15:04:05Z", true, true},
+		{Property{EdmDateTime, "yesterday"}, nil, false, false},
+		{Property{EdmGuid, "123e4567-e89b-12d3-a456-426614174000"}, "123e4567-e89b-12d3-a456-426614174000", true, true},
+		{Property{EdmGuid, "123e4567"}, nil, false, false},
+		{Property{EdmBinary, "aGk="}, "aGk=", true, true},
+		{Property{EdmBinary, "not base64"}, nil, false, false},
+		{Property{"Edm.Decimal", "1"}, nil, false, false},
+	}
+	for _, tt := range tests {
+		v, annotate, err := tt.p.jsonValue()
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("%v: jsonValue succeeded", tt.p)
+			}
+			continue
+		}
+		if err != nil || v != tt.want || annotate != tt.annotate {
+			t.Errorf("%v: jsonValue = %#v, %v, %v; want %#v, %v", tt.p, v, annotate, err, tt.want, tt.annotate)
+		}
+	}
+}
+
+func TestPropertyTypedValue(t *testing.T) {
+	tests := []struct {
+		p    Property
+		want interface{}
+	}{
+		{Property{EdmString, "1"}, "1"},
+		{Property{EdmInt32, "12"}, int64(12)},
+		{Property{EdmInt64, "9007199254740993"}, int64(9007199254740993)},
+		{Property{EdmInt64, "x"}, "x"},
+		{Property{EdmDouble, "0.25"}, 0.25},
+		{Property{EdmDouble, "NaN"}, "NaN"},
+		{Property{EdmBoolean, "false"}, false},
+		{Property{EdmDateTime, "2006-01-02T15:04:05Z"}, "2006-01-02T15:04:05Z"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.TypedValue(); got != tt.want {
+			t.Errorf("%v: TypedValue = %#v, want %#v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestEntityMarshalRoundTrip(t *testing.T) {
+	e := Entity{PartitionKey: "p", RowKey: "r", Properties: map[string]Property{
+		"Name":  {EdmString, "ada"},
+		"Age":   {EdmInt32, "36"},
+		"Count": {EdmInt64, "9007199254740993"},
+		"Ratio": {EdmDouble, "0.5"},
+		"On":    {EdmBoolean, "true"},
+		"Id":    {EdmGuid, "123e4567-e89b-12d3-a456-426614174000"},
+		"Blob":  {EdmBinary, "aGk="},
+	}}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wire map[string]interface{}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatal(err)
+	}
+	wantWire := map[string]interface{}{
+		"PartitionKey": "p", "RowKey": "r",
+		"Name": "ada", "Age": 36.0, "On": true,
+		"Count": "9007199254740993", "Count@odata.type": "Edm.Int64",
+		"Ratio": 0.5, "Ratio@odata.type": "Edm.Double",
+		"Id": "123e4567-e89b-12d3-a456-426614174000", "Id@odata.type": "Edm.Guid",
+		"Blob": "aGk=", "Blob@odata.type": "Edm.Binary",
+	}
+	if !reflect.DeepEqual(wire, wantWire) {
+		t.Errorf("wire form =\n%v\nwant\n%v", wire, wantWire)
+	}
+
+	var back Entity
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, e) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", back, e)
+	}
+
+	e.Properties["Bad"] = Property{EdmInt32, "x"}
+	if _, err := json.Marshal(e); err == nil || !strings.Contains(err.Error(), "property Bad (Edm.Int32)") {
+		t.Errorf("Marshal with a bad Int32 = %v", err)
+	}
+}
+
+func TestEntityPath(t *testing.T) {
+	tests := []struct{ table, pk, rk, want string }{
+		{"people", "p", "r", "/people(PartitionKey='p',RowKey='r')"},
+		{"people", "o'brien", "1", "/people(PartitionKey='o%27%27brien',RowKey='1')"},
+		{"people", "a b", "c/d", "/people(PartitionKey='a%20b',RowKey='c%2Fd')"},
+	}
+	for _, tt := range tests {
+		if got := entityPath(tt.table, tt.pk, tt.rk); got != tt.want {
+			t.Errorf("entityPath(%q, %q, %q) = %s, want %s", tt.table, tt.pk, tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestSaveEntityRequests(t *testing.T) {
+	e := Entity{PartitionKey: "p", RowKey: "r", Properties: map[string]Property{"N": {EdmInt32, "1"}}}
+	tests := []struct {
+		mode    WriteMode
+		etag    string
+		request string
+		ifMatch string
+		prefer  string
+	}{
+		{Insert, "", "POST /devstoreaccount1/people", "", "return-no-content"},
+		{Replace, "", "PUT /devstoreaccount1/people(PartitionKey='p',RowKey='r')", "*", ""},
+		{Merge, `W/"2"`, "MERGE /devstoreaccount1/people(PartitionKey='p',RowKey='r')", `W/"2"`, ""},
+		{Upsert, `W/"2"`, "PUT /devstoreaccount1/people(PartitionKey='p',RowKey='r')", "", ""},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, nil)
+		if err := c.SaveEntity("people", e, tt.mode, tt.etag); err != nil {
+			t.Fatalf("%v: %v", tt.mode, err)
+		}
+		r := fake.requests[0]
+		if got := r.Method + " " + r.URL; got != tt.request {
+			t.Errorf("%v: request %s, want %s", tt.mode, got, tt.request)
+		}
+		if got := r.Header.Get("If-Match"); got != tt.ifMatch {
+			t.Errorf("%v: If-Match = %q, want %q", tt.mode, got, tt.ifMatch)
+		}
+		if got := r.Header.Get("Prefer"); got != tt.prefer {
+			t.Errorf("%v: Prefer = %q, want %q", tt.mode, got, tt.prefer)
+		}
+		if got, want := string(r.Body), `{"N":1,"PartitionKey":"p","RowKey":"r"}`; got != want {
+			t.Errorf("%v: body = %s, want %s", tt.mode, got, want)
+		}
+	}
+}
+
+func TestDeleteEntityRequest(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	if err := c.DeleteEntity("people", "p", "r", ""); err != nil {
+		t.Fatal(err)
+	}
+	r := fake.requests[0]
+	if got, want := r.Method+" "+r.URL, "DELETE /devstoreaccount1/people(PartitionKey='p',RowKey='r')"; got != want {
+		t.Errorf("request %s, want %s", got, want)
+	}
+	if got := r.Header.Get("If-Match"); got != "*" {
+		t.Errorf("If-Match = %q, want *", got)
+	}
+}
@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRange is the largest body a single Put Range accepts.
+const maxRange = 4 << 20
+
+type shareList struct {
+	Shares []struct {
+		Name string `xml:"Name"`
+	} `xml:"Shares>Share"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type fileList struct {
+	Directories []struct {
+		Name string `xml:"Name"`
+	} `xml:"Entries>Directory"`
+	Files []struct {
+		Name string `xml:"Name"`
+	} `xml:"Entries>File"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// FileProperties are the system properties, SMB attributes and metadata of a
+// file or directory in a share.
+type FileProperties struct {
+	IsDir         bool
+	ContentLength int64
+	ContentType   string
+	ContentMD5    string
+	ETag          string
+	LastModified  time.Time
+	Attributes    string // SMB attributes, e.g. "ReadOnly | Archive"
+	CreationTime  time.Time
+	LastWriteTime time.Time
+	ChangeTime    time.Time
+	PermissionKey string
+	FileID        string
+	ParentID      string
+	Metadata      map[string]string
+}
+
+// ListShares implements Backend. Azurite has no file service, so against the
+// emulator this returns ErrServiceUnavailable unless a FileEndpoint is configured.
+func (c *Client) ListShares() ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		q := url.Values{"comp": {"list"}}
+		if marker != "" {
+			q.Set("marker", marker)
+		}
+		var res shareList
+		if err := c.getXML(serviceFile, "/", q, &res); err != nil {
+			return nil, err
+		}
+		for _, s := range res.Shares {
+			names = append(names, s.Name)
+		}
+		if res.NextMarker == "" {
+			return names, nil
+		}
+		marker = res.NextMarker
+	}
+}
+
+// ListFiles returns one page of the directories and files in dir of a share.
+// dir is "" for the root or a path ending in "/"; entry paths are relative to
+// the share, with directories ending in "/" like blob prefixes.
+func (c *Client) ListFiles(share, dir, marker string) (Page, error) {
+	q := url.Values{"restype": {"directory"}, "comp": {"list"}, "maxresults": {strconv.Itoa(PageSize)}}
+	if marker != "" {
+		q.Set("marker", marker)
+	}
+	var res fileList
+	if err := c.getXML(serviceFile, filePath(share, dir), q, &res); err != nil {
+		return Page{}, err
+	}
+	var page Page
+	for _, d := range res.Directories {
+		page.Entries = append(page.Entries, Entry{Name: d.Name + "/", Path: dir + d.Name + "/", IsDir: true})
+	}
+	for _, f := range res.Files {
+		page.Entries = append(page.Entries, Entry{Name: f.Name, Path: dir + f.Name})
+	}
+	page.Next = res.NextMarker
+	return page, nil
+}
+
+// filePath returns the escaped request path of a file or directory; a
+// trailing "/" is dropped, and "" is the share's root directory.
+func filePath(share, path string) string {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return "/" + url.PathEscape(share)
+	}
+	return "/" + url.PathEscape(share) + "/" + escapePath(path)
+}
+
+// setSMBDefaults sets the SMB properties the file service requires when
+// creating a file or directory: inherited permissions and the current time.
+func setSMBDefaults(req *http.Request, attributes string) {
+	req.Header.Set("x-ms-file-permission", "inherit")
+	req.Header.Set("x-ms-file-attributes", attributes)
+	req.Header.Set("x-ms-file-creation-time", "now")
+	req.Header.Set("x-ms-file-last-write-time", "now")
+}
+
+// CreateDirectory creates dir (a path relative to the share) in a share.
+// Its parent directory must already exist.
+func (c *Client) CreateDirectory(share, dir string) error {
+	req, err := c.newRequest(serviceFile, http.MethodPut, filePath(share, dir), url.Values{"restype": {"directory"}}, nil)
+	if err != nil {
+		return err
+	}
+	setSMBDefaults(req, "Directory")
+	return c.send(serviceFile, req)
+}
+
+// DeleteDirectory removes dir from a share. The service refuses to delete a
+// directory that is not empty.
+func (c *Client) DeleteDirectory(share, dir string) error {
+	req, err := c.newRequest(serviceFile, http.MethodDelete, filePath(share, dir), url.Values{"restype": {"directory"}}, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceFile, req)
+}
+
+// DeleteFile removes a file from a share.
+func (c *Client) DeleteFile(share, path string) error {
+	req, err := c.newRequest(serviceFile, http.MethodDelete, filePath(share, path), nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceFile, req)
+}
+
+// GetFileProperties reads the properties of a file, or of a directory when
+// path ends in "/", with a HEAD request.
+func (c *Client) GetFileProperties(share, path string) (FileProperties, error) {
+	isDir := path == "" || strings.HasSuffix(path, "/")
+	var q url.Values
+	if isDir {
+		q = url.Values{"restype": {"directory"}}
+	}
+	req, err := c.newRequest(serviceFile, http.MethodHead, filePath(share, path), q, nil)
+	if err != nil {
+		return FileProperties{}, err
+	}
+	resp, err := c.do(serviceFile, req)
+	if err != nil {
+		return FileProperties{}, err
+	}
+	resp.Body.Close()
+	h := resp.Header
+	props := FileProperties{
+		IsDir:         isDir,
+		ContentType:   h.Get("Content-Type"),
+		ContentMD5:    h.Get("Content-MD5"),
+		ETag:          h.Get("ETag"),
+		Attributes:    h.Get("x-ms-file-attributes"),
+		PermissionKey: h.Get("x-ms-file-permission-key"),
+		FileID:        h.Get("x-ms-file-id"),
+		ParentID:      h.Get("x-ms-file-parent-id"),
+		Metadata:      parseMetadata(h),
+	}
+	if !isDir {
+		props.ContentLength = resp.ContentLength
+	}
+	if t, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		props.LastModified = t
+	}
+	for name, t := range map[string]*time.Time{
+		"x-ms-file-creation-time":   &props.CreationTime,
+		"x-ms-file-last-write-time": &props.LastWriteTime,
+		"x-ms-file-change-time":     &props.ChangeTime,
+	} {
+		if v, err := time.Parse(time.RFC3339Nano, h.Get(name)); err == nil {
+			*t = v
+		}
+	}
+	return props, nil
+}
+
+// UploadShareFile copies the local file at path to share/name: Create File
+// sets its size, then the content goes up in Put Range calls of up to 4 MiB.
+func (c *Client) UploadShareFile(share, name, path string, progress Progress) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+	size := info.Size()
+
+	req, err := c.newRequest(serviceFile, http.MethodPut, filePath(share, name), nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-type", "file")
+	req.Header.Set("x-ms-content-length", strconv.FormatInt(size, 10))
+	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
+		req.Header.Set("x-ms-content-type", ct)
+	}
+	setSMBDefaults(req, "None")
+	if err := c.send(serviceFile, req); err != nil {
+		return err
+	}
+
+	buf := make([]byte, maxRange)
+	var offset int64
+	progress(0, size)
+	for offset < size {
+		n, err := io.ReadFull(f, buf[:min(int64(maxRange), size-offset)])
+		if err != nil {
+			return err
+		}
+		if err := c.putRange(share, name, offset, buf[:n]); err != nil {
+			return err
+		}
+		offset += int64(n)
+		progress(offset, size)
+	}
+	return nil
+}
+
+// putRange writes data into a file at offset.
+func (c *Client) putRange(share, name string, offset int64, data []byte) error {
+	req, err := c.newRequest(serviceFile, http.MethodPut, filePath(share, name), url.Values{"comp": {"range"}}, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+int64(len(data))-1, 10))
+	req.Header.Set("x-ms-write", "update")
+	sum := md5.Sum(data)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	return c.send(serviceFile, req)
+}
+
+// DownloadShareFile copies share/name to the local file at path in ranged
+// GETs, resuming an interrupted download of the same file version.
+func (c *Client) DownloadShareFile(share, name, path string, progress Progress) error {
+	props, err := c.GetFileProperties(share, name)
+	if err != nil {
+		return err
+	}
+	src := remoteFile{size: props.ContentLength, etag: props.ETag, md5: props.ContentMD5}
+	return downloadTo(path, src, func(etag string, start, end int64) ([]byte, error) {
+		return c.getFileRange(share, name, etag, start, end)
+	}, progress)
+}
+
+// getFileRange fetches bytes [start, end] of a file. The file service has no
+// If-Match on reads, so the returned ETag is compared instead.
+func (c *Client) getFileRange(share, name, etag string, start, end int64) ([]byte, error) {
+	req, err := c.newRequest(serviceFile, http.MethodGet, filePath(share, name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-range", "bytes="+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10))
+	req.Header.Set("x-ms-range-get-content-md5", "true")
+	resp, err := c.do(serviceFile, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("ETag"); etag != "" && got != "" && got != etag {
+		return nil, fmt.Errorf("file changed during download, start again")
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if want := resp.Header.Get("Content-MD5"); want != "" {
+		sum := md5.Sum(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("Content-MD5 mismatch at offset %d", start)
+		}
+	}
+	return data, nil
+}
+
+// CreateShare creates a share; quota is its size limit in GiB, 0 for the
+// service default.
+func (c *Client) CreateShare(name string, quota int) error {
+	req, err := c.newRequest(serviceFile, http.MethodPut, "/"+url.PathEscape(name), url.Values{"restype": {"share"}}, nil)
+	if err != nil {
+		return err
+	}
+	if quota > 0 {
+		req.Header.Set("x-ms-share-quota", strconv.Itoa(quota))
+	}
+	return c.send(serviceFile, req)
+}
+
+// DeleteShare deletes a share and everything in it.
+func (c *Client) DeleteShare(name string) error {
+	req, err := c.newRequest(serviceFile, http.MethodDelete, "/"+url.PathEscape(name), url.Values{"restype": {"share"}}, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceFile, req)
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFilePath(t *testing.T) {
+	tests := []struct{ share, path, want string }{
+		{"s", "", "/s"},
+		{"s", "dir/", "/s/dir"},
+		{"s", "dir/sub/f.txt", "/s/dir/sub/f.txt"},
+		{"s", "a b/c#d", "/s/a%20b/c%23d"},
+	}
+	for _, tt := range tests {
+		if got := filePath(tt.share, tt.path); got != tt.want {
+			t.Errorf("filePath(%q, %q) = %s, want %s", tt.share, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		fmt.Fprint(w, `<EnumerationResults><Entries>`+
+			`<File><Name>a.txt</Name><Properties><Content-Length>3</Content-Length></Properties></File>`+
+			`<Directory><Name>sub</Name></Directory>`+
+			`</Entries><NextMarker>m2</NextMarker></EnumerationResults>`)
+	})
+	page, err := c.ListFiles("s", "dir/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Page{Entries: []Entry{
+		{Name: "sub/", Path: "dir/sub/", IsDir: true},
+		{Name: "a.txt", Path: "dir/a.txt"},
+	}, Next: "m2"}
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("page =\n%+v\nwant\n%+v", page, want)
+	}
+	wantURL := "/devstoreaccount1/s/dir?comp=list&maxresults=" + strconv.Itoa(PageSize) + "&restype=directory"
+	if got := fake.requests[0].URL; got != wantURL {
+		t.Errorf("request %s, want %s", got, wantURL)
+	}
+}
+
+func TestFileServiceUnavailable(t *testing.T) {
+	c := NewClient(DevelopmentAccount())
+	if _, err := c.ListShares(); err != ErrServiceUnavailable {
+		t.Errorf("ListShares on Azurite = %v, want ErrServiceUnavailable", err)
+	}
+}
+
+func TestGetFileProperties(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		h := w.Header()
+		h.Set("Content-Length", "42")
+		h.Set("ETag", `"0x1"`)
+		h.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		h.Set("x-ms-file-attributes", "ReadOnly | Archive")
+		h.Set("x-ms-file-creation-time", "2006-01-02T15:04:05.1234567Z")
+		h.Set("x-ms-file-id", "13835128424026341376")
+		h.Set("x-ms-meta-Owner", "ops")
+	})
+	props, err := c.GetFileProperties("s", "dir/f.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props.IsDir || props.ContentLength != 42 || props.ETag != `"0x1"` || props.Attributes != "ReadOnly | Archive" || props.FileID != "13835128424026341376" {
+		t.Errorf("props = %+v", props)
+	}
+	if props.LastModified.Year() != 2006 || props.CreationTime.Nanosecond() != 123456700 {
+		t.Errorf("times = %v, %v", props.LastModified, props.CreationTime)
+	}
+	if !reflect.DeepEqual(props.Metadata, map[string]string{"owner": "ops"}) {
+		t.Errorf("Metadata = %v", props.Metadata)
+	}
+
+	dir, err := c.GetFileProperties("s", "dir/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dir.IsDir || dir.ContentLength != 0 {
+		t.Errorf("directory props = %+v", dir)
+	}
+	if got := fake.requests[1].Method + " " + fake.requests[1].URL; got != "HEAD /devstoreaccount1/s/dir?restype=directory" {
+		t.Errorf("directory request %s", got)
+	}
+}
+
+func TestUploadShareFileRanges(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	data := bytes.Repeat([]byte("x"), maxRange+10)
+	path := writeTemp(t, "big.txt", string(data))
+	if err := c.UploadShareFile("s", "dir/big.txt", path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 3 {
+		t.Fatalf("%d requests, want Create File and two ranges", len(fake.requests))
+	}
+	create := fake.requests[0]
+	if create.Method+" "+create.URL != "PUT /devstoreaccount1/s/dir/big.txt" {
+		t.Errorf("create = %s %s", create.Method, create.URL)
+	}
+	for name, want := range map[string]string{
+		"x-ms-type":            "file",
+		"x-ms-content-length":  strconv.Itoa(len(data)),
+		"x-ms-content-type":    "text/plain; charset=utf-8",
+		"x-ms-file-attributes": "None",
+		"x-ms-file-permission": "inherit",
+	} {
+		if got := create.Header.Get(name); got != want {
+			t.Errorf("create %s = %q, want %q", name, got, want)
+		}
+	}
+
+	wantRanges := []string{fmt.Sprintf("bytes=0-%d", maxRange-1), fmt.Sprintf("bytes=%d-%d", maxRange, maxRange+9)}
+	for i, r := range fake.requests[1:] {
+		if r.URL != "/devstoreaccount1/s/dir/big.txt?comp=range" || r.Header.Get("x-ms-write") != "update" {
+			t.Errorf("range %d = %s %v", i, r.URL, r.Header)
+		}
+		if got := r.Header.Get("x-ms-range"); got != wantRanges[i] {
+			t.Errorf("range %d x-ms-range = %s, want %s", i, got, wantRanges[i])
+		}
+		sum := md5.Sum(r.Body)
+		if got := r.Header.Get("Content-MD5"); got != base64.StdEncoding.EncodeToString(sum[:]) {
+			t.Errorf("range %d Content-MD5 = %s", i, got)
+		}
+	}
+}
+
+func TestGetFileRange(t *testing.T) {
+	sum := md5.Sum([]byte("hello"))
+	goodMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	tests := []struct {
+		name, etag, md5 string
+		wantErr         string
+	}{
+		{name: "checked", etag: `"v1"`, md5: goodMD5},
+		{name: "no checksum", etag: `"v1"`},
+		{name: "changed", etag: `"v2"`, wantErr: "file changed during download"},
+		{name: "corrupt", etag: `"v1"`, md5: "AAAAAAAAAAAAAAAAAAAAAA==", wantErr: "Content-MD5 mismatch at offset 0"},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+			w.Header().Set("ETag", tt.etag)
+			if tt.md5 != "" {
+				w.Header().Set("Content-MD5", tt.md5)
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("hello"))
+		})
+		data, err := c.getFileRange("s", "f", `"v1"`, 0, 4)
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("%s: getFileRange = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(data) != "hello" {
+			t.Errorf("%s: getFileRange = %q, %v", tt.name, data, err)
+		}
+		h := fake.requests[0].Header
+		if h.Get("x-ms-range") != "bytes=0-4" || h.Get("x-ms-range-get-content-md5") != "true" {
+			t.Errorf("%s: headers %v", tt.name, h)
+		}
+	}
+}
+
+func TestShareRequests(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	if err := c.CreateShare("s", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateShare("t", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateDirectory("s", "dir/"); err != nil {
+		t.Fatal(err)
+	}
+	if r := fake.requests[0]; r.URL != "/devstoreaccount1/s?restype=share" || r.Header.Get("x-ms-share-quota") != "5" {
+		t.Errorf("create share = %s %v", r.URL, r.Header)
+	}
+	if r := fake.requests[1]; r.Header.Get("x-ms-share-quota") != "" {
+		t.Errorf("create share without quota sent %q", r.Header.Get("x-ms-share-quota"))
+	}
+	if r := fake.requests[2]; r.Method+" "+r.URL != "PUT /devstoreaccount1/s/dir?restype=directory" || r.Header.Get("x-ms-file-attributes") != "Directory" {
+		t.Errorf("create directory = %s %s %v", r.Method, r.URL, r.Header)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReadyTimeout is how long EnsureAzurite waits for a started instance to answer.
+const ReadyTimeout = 60 * time.Second
+
+// ServiceHealth is the readiness of one storage service.
+type ServiceHealth struct {
+	Service string // "blob", "queue" or "table"
+	Address string // host:port of its endpoint
+	Ready   bool
+	Err     error // why the last probe failed
+}
+
+func (h ServiceHealth) String() string {
+	switch {
+	case h.Ready:
+		return h.Service + " ready"
+	case h.Err != nil:
+		return fmt.Sprintf("%s: %v", h.Service, h.Err)
+	}
+	return h.Service + " starting"
+}
+
+var probedServices = []struct {
+	name string
+	svc  service
+}{
+	{"blob", serviceBlob},
+	{"queue", serviceQueue},
+	{"table", serviceTable},
+}
+
+// Probe checks each service once: that its port accepts connections, then
+// that a signed list request succeeds. Services the account has no endpoint
+// for are left out.
+func (c *Client) Probe(ctx context.Context) []ServiceHealth {
+	var health []ServiceHealth
+	for _, s := range probedServices {
+		base := c.endpoint(s.svc)
+		if base == "" {
+			continue
+		}
+		h := ServiceHealth{Service: s.name, Address: endpointAddress(base)}
+		h.Err = c.probe(ctx, s.svc, h.Address)
+		h.Ready = h.Err == nil
+		health = append(health, h)
+	}
+	return health
+}
+
+func (c *Client) probe(ctx context.Context, svc service, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("not listening on %s", address)
+	}
+	conn.Close()
+
+	path, q := "/", url.Values{"comp": {"list"}, "maxresults": {"1"}}
+	if svc == serviceTable {
+		path, q = "/Tables", url.Values{"$top": {"1"}}
+	}
+	req, err := c.newRequest(svc, http.MethodGet, path, q, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(svc, req.WithContext(ctx))
+}
+
+// endpointAddress returns the host:port an endpoint URL connects to.
+func endpointAddress(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "443"
+	if u.Scheme == "http" {
+		port = "80"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// WaitReady probes the services until all are ready, backing off from a
+// quarter second to two seconds between rounds, and passes every round to
+// report. It gives up after timeout or when ctx is done.
+func (c *Client) WaitReady(ctx context.Context, timeout time.Duration, report func([]ServiceHealth)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	delay := 250 * time.Millisecond
+	for {
+		health := c.Probe(ctx)
+		if report != nil {
+			report(health)
+		}
+		var failed []string
+		for _, h := range health {
+			if !h.Ready {
+				failed = append(failed, h.String())
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %s", timeout, strings.Join(failed, "; "))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 2*time.Second)
+	}
+}
+
+// checkPortsFree fails if another process holds one of the instance's ports.
+func checkPortsFree(cfg AzuriteConfig) error {
+	ports := []struct {
+		service string
+		port    int
+	}{
+		{"blob", cfg.BlobPort},
+		{"queue", cfg.QueuePort},
+		{"table", cfg.TablePort},
+	}
+	for _, p := range ports {
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", p.port))
+		if err != nil {
+			return fmt.Errorf("port %d for the %s service of %s is already in use by another process", p.port, p.service, cfg.Name)
+		}
+		l.Close()
+	}
+	return nil
+}
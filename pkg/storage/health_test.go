@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointAddress(t *testing.T) {
+	tests := []struct{ endpoint, want string }{
+		{"http://127.0.0.1:10000/devstoreaccount1", "127.0.0.1:10000"},
+		{"https://acct.blob.core.windows.net", "acct.blob.core.windows.net:443"},
+		{"http://azurite/devstoreaccount1", "azurite:80"},
+		{"http://[::1]/acct", "[::1]:80"},
+	}
+	for _, tt := range tests {
+		if got := endpointAddress(tt.endpoint); got != tt.want {
+			t.Errorf("endpointAddress(%q) = %s, want %s", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestServiceHealthString(t *testing.T) {
+	tests := []struct {
+		h    ServiceHealth
+		want string
+	}{
+		{ServiceHealth{Service: "blob", Ready: true}, "blob ready"},
+		{ServiceHealth{Service: "queue", Err: errors.New("not listening on x")}, "queue: not listening on x"},
+		{ServiceHealth{Service: "table"}, "table starting"},
+	}
+	for _, tt := range tests {
+		if got := tt.h.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if strings.HasSuffix(r.URL.Path, "/Tables") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `<EnumerationResults/>`)
+	})
+	health := c.Probe(context.Background())
+	if len(health) != 3 {
+		t.Fatalf("%d services probed, want 3 (no file endpoint on Azurite)", len(health))
+	}
+	for _, h := range health[:2] {
+		if !h.Ready || h.Err != nil {
+			t.Errorf("%s = %v", h.Service, h)
+		}
+	}
+	var rerr *ResponseError
+	if health[2].Ready || !errors.As(health[2].Err, &rerr) || rerr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("table = %v", health[2])
+	}
+	want := []string{
+		"/devstoreaccount1/?comp=list&maxresults=1",
+		"/devstoreaccount1/?comp=list&maxresults=1",
+		"/devstoreaccount1/Tables?%24top=1",
+	}
+	for i, r := range fake.requests {
+		if r.URL != want[i] {
+			t.Errorf("probe %d = %s, want %s", i, r.URL, want[i])
+		}
+	}
+}
+
+func TestProbeNotListening(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	account := DevelopmentAccount()
+	account.BlobEndpoint, account.QueueEndpoint, account.TableEndpoint = "http://"+address+"/a", "", ""
+	health := NewClient(account).Probe(context.Background())
+	if len(health) != 1 || health[0].Ready || health[0].Err == nil || health[0].Err.Error() != "not listening on "+address {
+		t.Errorf("Probe = %v", health)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	ready := false
+	c, _ := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	rounds := 0
+	err := c.WaitReady(context.Background(), 5*time.Second, func(health []ServiceHealth) {
+		rounds++
+		ready = true
+	})
+	if err != nil || rounds != 2 {
+		t.Errorf("WaitReady = %v after %d rounds, want nil after 2", err, rounds)
+	}
+
+	ready = false
+	err = c.WaitReady(context.Background(), 100*time.Millisecond, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "not ready after 100ms: blob: storage: 503") {
+		t.Errorf("WaitReady = %v", err)
+	}
+}
+
+func TestCheckPortsFree(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	cfg := DefaultAzuriteConfig()
+	cfg.QueuePort = l.Addr().(*net.TCPAddr).Port
+	err = checkPortsFree(cfg)
+	if want := fmt.Sprintf("port %d for the queue service of default is already in use", cfg.QueuePort); err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("checkPortsFree = %v, want %q", err, want)
+	}
+}
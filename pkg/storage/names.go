@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Containers, queues and shares: lowercase letters, digits and single
+	// hyphens, starting and ending with a letter or digit.
+	dnsName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// Tables: letters and digits, starting with a letter.
+	tableName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	// Metadata names must be valid C# identifiers.
+	metadataName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ValidateName checks name against the service's naming rules for kind, so
+// mistakes are reported before a request is sent.
+func ValidateName(kind ResourceKind, name string) error {
+	if len(name) < 3 || len(name) > 63 {
+		return fmt.Errorf("name must be 3 to 63 characters long")
+	}
+	switch kind {
+	case Containers, Queues, Shares:
+		if !dnsName.MatchString(name) {
+			return fmt.Errorf("name may only contain lowercase letters, digits and single hyphens, and must start and end with a letter or digit")
+		}
+	case Tables:
+		if !tableName.MatchString(name) {
+			return fmt.Errorf("name may only contain letters and digits, and must start with a letter")
+		}
+		if strings.EqualFold(name, "tables") {
+			return fmt.Errorf("%q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// ValidateMetadataName checks that a metadata key is a valid C# identifier.
+func ValidateMetadataName(name string) error {
+	if !metadataName.MatchString(name) {
+		return fmt.Errorf("metadata name %q must start with a letter or underscore and contain only letters, digits and underscores", name)
+	}
+	return nil
+}
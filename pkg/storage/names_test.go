@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		kind ResourceKind
+		name string
+		ok   bool
+	}{
+		{Containers, "logs", true},
+		{Containers, "my-logs-2", true},
+		{Containers, "ab", false},
+		{Containers, strings.Repeat("a", 64), false},
+		{Containers, "Logs", false},
+		{Containers, "my--logs", false},
+		{Containers, "-logs", false},
+		{Containers, "logs-", false},
+		{Containers, "my_logs", false},
+		{Queues, "jobs", true},
+		{Queues, "Jobs", false},
+		{Shares, "share-1", true},
+		{Tables, "People2", true},
+		{Tables, "2People", false},
+		{Tables, "my-table", false},
+		{Tables, "Tables", false},
+		{Tables, "tables", false},
+	}
+	for _, tt := range tests {
+		err := ValidateName(tt.kind, tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateName(%v, %q) = %v, want ok %v", tt.kind, tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Native process ---
+
+// nativeRuntime runs the azurite binary from npm as a background process.
+// Its PID, output and (for named volumes) data live in the instance's
+// directory, so later invocations can find, stop and tail it.
+type nativeRuntime struct{}
+
+func (nativeRuntime) Name() string { return "azurite" }
+
+// instanceDir returns the directory holding the instance's PID file and log.
+func instanceDir(cfg AzuriteConfig) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "azstorecli", "instances", cfg.Name), nil
+}
+
+// pid returns the process recorded for the instance and whether it still
+// runs. The PID file also holds the process's start time, so that a PID
+// reused after a reboot or crash is not taken for azurite.
+func (nativeRuntime) pid(cfg AzuriteConfig) (pid int, running bool) {
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "azurite.pid"))
+	if err != nil {
+		return 0, false
+	}
+	id, recorded, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	pid, err = strconv.Atoi(id)
+	if err != nil {
+		return 0, false
+	}
+	start, alive := processStart(pid)
+	return pid, alive && recorded != "" && start == recorded
+}
+
+func (r nativeRuntime) ID(cfg AzuriteConfig) string {
+	if pid, running := r.pid(cfg); running {
+		return strconv.Itoa(pid)
+	}
+	return ""
+}
+
+// location returns the data directory of the instance: the volume's host
+// path, or a directory named after the volume inside dir.
+func (nativeRuntime) location(cfg AzuriteConfig, dir string) (string, error) {
+	location, err := mountSource(cfg.Volume)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(dir, location)
+	}
+	return location, nil
+}
+
+// args returns the azurite command line.
+func (r nativeRuntime) args(cfg AzuriteConfig, dir string) ([]string, error) {
+	args := []string{
+		"--blobHost", "127.0.0.1", "--blobPort", strconv.Itoa(cfg.BlobPort),
+		"--queueHost", "127.0.0.1", "--queuePort", strconv.Itoa(cfg.QueuePort),
+		"--tableHost", "127.0.0.1", "--tablePort", strconv.Itoa(cfg.TablePort),
+	}
+	if cfg.InMemory {
+		args = append(args, "--inMemoryPersistence")
+	} else {
+		location, err := r.location(cfg, dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(location, 0o755); err != nil {
+			return nil, err
+		}
+		args = append(args, "--location", location)
+	}
+	if cfg.DebugLog != "" {
+		path, err := hostPath(cfg.DebugLog)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--debug", path)
+	}
+	return append(args, cfg.serviceFlags()...), nil
+}
+
+func (r nativeRuntime) Ensure(cfg AzuriteConfig, logf func(string)) (string, error) {
+	if id := r.ID(cfg); id != "" {
+		logf(fmt.Sprintf("Using existing Azurite process: %s\n", id))
+		return id, nil
+	}
+	bin, err := exec.LookPath("azurite")
+	if err != nil {
+		return "", fmt.Errorf("azurite not found; install it with npm install -g azurite")
+	}
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	args, err := r.args(cfg, dir)
+	if err != nil {
+		return "", err
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, "azurite.log"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	// Reap the process if it ends while we are still running; it outlives us otherwise
+	go cmd.Wait()
+
+	id := strconv.Itoa(cmd.Process.Pid)
+	start, _ := processStart(cmd.Process.Pid)
+	if err := os.WriteFile(filepath.Join(dir, "azurite.pid"), []byte(id+"\n"+start+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	os.Remove(filepath.Join(dir, "azurite.paused"))
+	logf(fmt.Sprintf("Azurite process started: %s\n", id))
+	return id, nil
+}
+
+func (r nativeRuntime) State(cfg AzuriteConfig) (id, state string, err error) {
+	pid, running := r.pid(cfg)
+	switch {
+	case running && r.paused(cfg):
+		return strconv.Itoa(pid), "paused", nil
+	case running:
+		return strconv.Itoa(pid), "running", nil
+	case pid != 0:
+		return strconv.Itoa(pid), "exited", nil
+	}
+	return "", "", nil
+}
+
+func (r nativeRuntime) Stop(cfg AzuriteConfig) error {
+	pid, running := r.pid(cfg)
+	if !running {
+		return fmt.Errorf("no running azurite process for %s", cfg.Name)
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if r.paused(cfg) {
+		// A stopped process only acts on the termination once resumed
+		if err := r.Unpause(cfg); err != nil {
+			return err
+		}
+	}
+	if err := terminate(p); err != nil {
+		return err
+	}
+	for i := 0; i < 50 && r.ID(cfg) != ""; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if r.ID(cfg) != "" {
+		return fmt.Errorf("azurite process %d did not exit", pid)
+	}
+	return nil
+}
+
+// Pause stops the process with a signal; a marker file records that it is
+// paused rather than running.
+func (r nativeRuntime) Pause(cfg AzuriteConfig) error {
+	return r.signal(cfg, suspend, true)
+}
+
+func (r nativeRuntime) Unpause(cfg AzuriteConfig) error {
+	return r.signal(cfg, resume, false)
+}
+
+func (r nativeRuntime) signal(cfg AzuriteConfig, send func(*os.Process) error, paused bool) error {
+	pid, running := r.pid(cfg)
+	if !running {
+		return fmt.Errorf("no running azurite process for %s", cfg.Name)
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := send(p); err != nil {
+		return err
+	}
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return err
+	}
+	marker := filepath.Join(dir, "azurite.paused")
+	if paused {
+		return os.WriteFile(marker, nil, 0o644)
+	}
+	if err := os.Remove(marker); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (r nativeRuntime) paused(cfg AzuriteConfig) bool {
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, "azurite.paused"))
+	return err == nil
+}
+
+// Remove stops the process and deletes its PID file; the data stays.
+func (r nativeRuntime) Remove(cfg AzuriteConfig) error {
+	if r.ID(cfg) != "" {
+		if err := r.Stop(cfg); err != nil {
+			return err
+		}
+	}
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"azurite.pid", "azurite.paused"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nativeRuntime) Pull(cfg AzuriteConfig, logf func(string)) error {
+	return fmt.Errorf("a local azurite is updated with npm install -g azurite")
+}
+
+// dataDir returns the instance's data directory.
+func (r nativeRuntime) dataDir(cfg AzuriteConfig) (string, error) {
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	return r.location(cfg, dir)
+}
+
+func (r nativeRuntime) ExportData(cfg AzuriteConfig, w io.Writer) error {
+	dir, err := r.dataDir(cfg)
+	if err != nil {
+		return err
+	}
+	return writeTarGz(w, dir)
+}
+
+func (r nativeRuntime) RemoveData(cfg AzuriteConfig, names []string) error {
+	dir, err := r.dataDir(cfg)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nativeRuntime) Version(cfg AzuriteConfig) string {
+	out, err := exec.Command("azurite", "--version").Output()
+	if err != nil {
+		return "azurite"
+	}
+	return "azurite " + strings.TrimSpace(string(out))
+}
+
+func (r nativeRuntime) Logs(ctx context.Context, cfg AzuriteConfig, follow bool, w io.Writer) error {
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(dir, "azurite.log"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no azurite log for %s", cfg.Name)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for {
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		if !follow || r.ID(cfg) == "" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
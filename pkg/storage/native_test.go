@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestProcessStart(t *testing.T) {
+	start, ok := processStart(os.Getpid())
+	if !ok || start == "" {
+		t.Fatalf("processStart(self) = %q, %v", start, ok)
+	}
+	if again, _ := processStart(os.Getpid()); again != start {
+		t.Errorf("start time changed from %q to %q", start, again)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := processStart(cmd.Process.Pid); ok {
+		t.Errorf("processStart reports exited process %d as running", cmd.Process.Pid)
+	}
+}
+
+func TestNativePIDFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("UserConfigDir is only redirected through XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := DefaultAzuriteConfig()
+	dir, err := instanceDir(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	self := strconv.Itoa(os.Getpid())
+	start, _ := processStart(os.Getpid())
+
+	tests := []struct {
+		name    string
+		content string
+		running bool
+	}{
+		{"recorded start matches", self + "\n" + start + "\n", true},
+		{"PID reused by another process", self + "\n" + start + "0\n", false},
+		{"no start time recorded", self + "\n", false},
+		{"garbage", "azurite\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(dir, "azurite.pid"), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, running := (nativeRuntime{}).pid(cfg); running != tt.running {
+				t.Errorf("running = %v, want %v", running, tt.running)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !windows
+
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detach starts cmd in its own session so it survives the terminal closing.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processStart returns when process pid started, as an opaque string, and
+// false if there is no such process we may signal. EPERM means the PID now
+// belongs to another user, so it is not ours either.
+func processStart(pid int) (string, bool) {
+	if pid <= 0 || syscall.Kill(pid, 0) != nil {
+		return "", false
+	}
+	if stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err == nil {
+		// The command name may contain spaces; the fields after it do not.
+		// State is field 3 and the start time in clock ticks field 22.
+		_, after, _ := strings.Cut(string(stat), ") ")
+		fields := strings.Fields(after)
+		if len(fields) < 20 || fields[0] == "Z" {
+			return "", false
+		}
+		return fields[19], true
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	start := strings.TrimSpace(string(out))
+	return start, err == nil && start != ""
+}
+
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
+
+func suspend(p *os.Process) error {
+	return p.Signal(syscall.SIGSTOP)
+}
+
+func resume(p *os.Process) error {
+	return p.Signal(syscall.SIGCONT)
+}
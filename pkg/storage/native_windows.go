@@ -0,0 +1,53 @@
+//go:build windows
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// detach starts cmd in its own process group so Ctrl+C does not reach it.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// stillActive is the exit code of a process that has not exited.
+const stillActive = 259
+
+// processStart returns when process pid was created, as an opaque string,
+// and false if there is no such running process we may query.
+func processStart(pid int) (string, bool) {
+	if pid <= 0 {
+		return "", false
+	}
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil || code != stillActive {
+		return "", false
+	}
+	var created, exited, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(created.Nanoseconds(), 10), true
+}
+
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
+
+func suspend(p *os.Process) error {
+	return errors.New("pausing a local azurite is not supported on Windows")
+}
+
+func resume(p *os.Process) error {
+	return errors.New("pausing a local azurite is not supported on Windows")
+}
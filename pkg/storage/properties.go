@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const metaPrefix = "x-ms-meta-"
+
+// BlobHTTPHeaders are the standard HTTP headers stored with a blob and
+// returned when it is read.
+type BlobHTTPHeaders struct {
+	ContentType        string
+	ContentEncoding    string
+	ContentLanguage    string
+	ContentDisposition string
+	CacheControl       string
+	ContentMD5         string // base64, empty if the blob has none
+}
+
+// BlobProperties are the system properties and user metadata of a blob.
+type BlobProperties struct {
+	BlobHTTPHeaders
+	ContentLength int64
+	ETag          string
+	LastModified  time.Time
+	BlobType      string // BlockBlob, PageBlob or AppendBlob
+	AccessTier    string
+	LeaseState    string
+	LeaseStatus   string
+	Metadata      map[string]string
+}
+
+// GetBlobProperties reads the properties and metadata of a blob with a HEAD request.
+func (c *Client) GetBlobProperties(container, blob string) (BlobProperties, error) {
+	req, err := c.newRequest(serviceBlob, http.MethodHead, blobPath(container, blob), nil, nil)
+	if err != nil {
+		return BlobProperties{}, err
+	}
+	resp, err := c.do(serviceBlob, req)
+	if err != nil {
+		return BlobProperties{}, err
+	}
+	resp.Body.Close()
+	props := parseBlobProperties(resp.Header)
+	props.ContentLength = resp.ContentLength
+	return props, nil
+}
+
+func parseBlobProperties(h http.Header) BlobProperties {
+	props := BlobProperties{
+		BlobHTTPHeaders: BlobHTTPHeaders{
+			ContentType:        h.Get("Content-Type"),
+			ContentEncoding:    h.Get("Content-Encoding"),
+			ContentLanguage:    h.Get("Content-Language"),
+			ContentDisposition: h.Get("Content-Disposition"),
+			CacheControl:       h.Get("Cache-Control"),
+			ContentMD5:         h.Get("Content-MD5"),
+		},
+		ETag:        h.Get("ETag"),
+		BlobType:    h.Get("x-ms-blob-type"),
+		AccessTier:  h.Get("x-ms-access-tier"),
+		LeaseState:  h.Get("x-ms-lease-state"),
+		LeaseStatus: h.Get("x-ms-lease-status"),
+		Metadata:    parseMetadata(h),
+	}
+	if n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil {
+		props.ContentLength = n
+	}
+	if t, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		props.LastModified = t
+	}
+	return props
+}
+
+// parseMetadata collects the x-ms-meta-* headers of a reply.
+func parseMetadata(h http.Header) map[string]string {
+	metadata := map[string]string{}
+	for name, values := range h {
+		// Go canonicalizes header names, so metadata keys come back lowercased
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, metaPrefix) && len(values) > 0 {
+			metadata[strings.TrimPrefix(lower, metaPrefix)] = values[0]
+		}
+	}
+	return metadata
+}
+
+// setMetadata adds metadata to req as x-ms-meta-* headers. The entries are
+// set directly so the names go out as given; Header.Set would canonicalize
+// "myKey" to "Mykey".
+func setMetadata(req *http.Request, metadata map[string]string) {
+	for k, v := range metadata {
+		req.Header[metaPrefix+k] = []string{v}
+	}
+}
+
+// SetBlobMetadata replaces all user metadata of a blob. A non-empty etag makes
+// the call fail with 412 if the blob changed since it was read. Names keep
+// their case on the service, but GetBlobProperties returns them lowercased.
+func (c *Client) SetBlobMetadata(container, blob string, metadata map[string]string, etag string) error {
+	req, err := c.newRequest(serviceBlob, http.MethodPut, blobPath(container, blob), url.Values{"comp": {"metadata"}}, nil)
+	if err != nil {
+		return err
+	}
+	setMetadata(req, metadata)
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	return c.send(serviceBlob, req)
+}
+
+// SetBlobHTTPHeaders replaces the HTTP headers of a blob. Headers left empty
+// are cleared, so callers should start from the current BlobProperties.
+func (c *Client) SetBlobHTTPHeaders(container, blob string, headers BlobHTTPHeaders, etag string) error {
+	req, err := c.newRequest(serviceBlob, http.MethodPut, blobPath(container, blob), url.Values{"comp": {"properties"}}, nil)
+	if err != nil {
+		return err
+	}
+	set := func(name, value string) {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	set("x-ms-blob-content-type", headers.ContentType)
+	set("x-ms-blob-content-encoding", headers.ContentEncoding)
+	set("x-ms-blob-content-language", headers.ContentLanguage)
+	set("x-ms-blob-content-disposition", headers.ContentDisposition)
+	set("x-ms-blob-cache-control", headers.CacheControl)
+	set("x-ms-blob-content-md5", headers.ContentMD5)
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	return c.send(serviceBlob, req)
+}
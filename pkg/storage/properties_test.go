@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSetMetadataKeepsNames(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPut, "http://127.0.0.1:10000/devstoreaccount1/c/b?comp=metadata", nil)
+	setMetadata(req, map[string]string{"myKey": "1", "_other": "two words"})
+
+	if got := req.Header["x-ms-meta-myKey"]; !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("x-ms-meta-myKey = %q", got)
+	}
+	if _, ok := req.Header["X-Ms-Meta-Mykey"]; ok {
+		t.Error("metadata name was canonicalized")
+	}
+	if got, want := canonicalizedHeaders(req), "x-ms-meta-_other:two words\nx-ms-meta-mykey:1\n"; got != want {
+		t.Errorf("canonicalizedHeaders = %q, want %q", got, want)
+	}
+}
+
+func TestParseBlobProperties(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("Content-Length", "42")
+	h.Set("Content-MD5", "1B2M2Y8AsgTpgAmY7PhCfg==")
+	h.Set("ETag", `"0x8D"`)
+	h.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	h.Set("x-ms-blob-type", "BlockBlob")
+	h.Set("x-ms-access-tier", "Hot")
+	h.Set("x-ms-meta-Owner", "ops")
+	h.Set("x-ms-meta-build_id", "7")
+
+	props := parseBlobProperties(h)
+	if props.ContentType != "application/json" || props.ContentLength != 42 || props.ContentMD5 != "1B2M2Y8AsgTpgAmY7PhCfg==" {
+		t.Errorf("headers = %+v, length %d", props.BlobHTTPHeaders, props.ContentLength)
+	}
+	if props.ETag != `"0x8D"` || props.BlobType != "BlockBlob" || props.AccessTier != "Hot" {
+		t.Errorf("props = %+v", props)
+	}
+	if props.LastModified.Year() != 2006 {
+		t.Errorf("LastModified = %v", props.LastModified)
+	}
+	want := map[string]string{"owner": "ops", "build_id": "7"}
+	if !reflect.DeepEqual(props.Metadata, want) {
+		t.Errorf("Metadata = %v, want %v", props.Metadata, want)
+	}
+}
+
+func TestValidateMetadataName(t *testing.T) {
+	for _, name := range []string{"owner", "_x", "Build2"} {
+		if err := ValidateMetadataName(name); err != nil {
+			t.Errorf("ValidateMetadataName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "2x", "with-dash", "with space", "dot.ted"} {
+		if err := ValidateMetadataName(name); err == nil {
+			t.Errorf("ValidateMetadataName(%q) succeeded", name)
+		}
+	}
+}
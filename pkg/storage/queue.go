@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxMessages is the most messages a single Peek or Get Messages call returns.
+const maxMessages = 32
+
+// releaseTimeout is how long a message stays hidden between being dequeued
+// for its pop receipt and being deleted or updated.
+const releaseTimeout = 30 * time.Second
+
+type queueList struct {
+	Queues []struct {
+		Name string `xml:"Name"`
+	} `xml:"Queues>Queue"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type messageList struct {
+	Messages []struct {
+		MessageID       string `xml:"MessageId"`
+		InsertionTime   string `xml:"InsertionTime"`
+		ExpirationTime  string `xml:"ExpirationTime"`
+		PopReceipt      string `xml:"PopReceipt"`
+		TimeNextVisible string `xml:"TimeNextVisible"`
+		DequeueCount    int    `xml:"DequeueCount"`
+		MessageText     string `xml:"MessageText"`
+	} `xml:"QueueMessage"`
+}
+
+// MessageEncoding is how a message body is stored in the queue. The Azure
+// Functions queue trigger expects base64 unless the host is configured otherwise.
+type MessageEncoding int
+
+const (
+	EncodingBase64 MessageEncoding = iota
+	EncodingText
+)
+
+func (e MessageEncoding) String() string {
+	if e == EncodingText {
+		return "text"
+	}
+	return "base64"
+}
+
+// ParseMessageEncoding accepts "base64"/"b64" and "text"/"raw".
+func ParseMessageEncoding(s string) (MessageEncoding, error) {
+	switch strings.ToLower(s) {
+	case "base64", "b64":
+		return EncodingBase64, nil
+	case "text", "raw":
+		return EncodingText, nil
+	}
+	return 0, fmt.Errorf("unknown message encoding %q (want base64 or text)", s)
+}
+
+// EncodeMessage returns the queue text for body in the given encoding.
+func EncodeMessage(body string, enc MessageEncoding) string {
+	if enc == EncodingBase64 {
+		return base64.StdEncoding.EncodeToString([]byte(body))
+	}
+	return body
+}
+
+// DecodeMessage guesses whether text is base64 by decoding it and checking
+// the result is printable UTF-8, and returns the decoded body if so.
+func DecodeMessage(text string) (string, MessageEncoding) {
+	if text == "" || len(text)%4 != 0 {
+		return text, EncodingText
+	}
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil || !utf8.Valid(data) {
+		return text, EncodingText
+	}
+	for _, r := range string(data) {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			return text, EncodingText
+		}
+	}
+	return string(data), EncodingBase64
+}
+
+// QueueMessage is a message as returned by Peek or Get Messages.
+// Text is the raw queue content; Body is Text decoded according to Encoding.
+// PopReceipt is only set for messages that were dequeued.
+type QueueMessage struct {
+	ID             string
+	InsertionTime  time.Time
+	ExpirationTime time.Time
+	DequeueCount   int
+	Text           string
+	Body           string
+	Encoding       MessageEncoding
+	PopReceipt     string
+}
+
+// ListQueues implements Backend.
+func (c *Client) ListQueues() ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		q := url.Values{"comp": {"list"}}
+		if marker != "" {
+			q.Set("marker", marker)
+		}
+		var res queueList
+		if err := c.getXML(serviceQueue, "/", q, &res); err != nil {
+			return nil, err
+		}
+		for _, qu := range res.Queues {
+			names = append(names, qu.Name)
+		}
+		if res.NextMarker == "" {
+			return names, nil
+		}
+		marker = res.NextMarker
+	}
+}
+
+func messagesPath(queue string) string {
+	return "/" + url.PathEscape(queue) + "/messages"
+}
+
+func (res messageList) messages() []QueueMessage {
+	msgs := make([]QueueMessage, 0, len(res.Messages))
+	for _, m := range res.Messages {
+		msg := QueueMessage{
+			ID:           m.MessageID,
+			DequeueCount: m.DequeueCount,
+			Text:         m.MessageText,
+			PopReceipt:   m.PopReceipt,
+		}
+		msg.Body, msg.Encoding = DecodeMessage(m.MessageText)
+		msg.InsertionTime, _ = http.ParseTime(m.InsertionTime)
+		msg.ExpirationTime, _ = http.ParseTime(m.ExpirationTime)
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// PeekMessages returns up to 32 messages from the front of a queue without
+// changing their visibility.
+func (c *Client) PeekMessages(queue string) ([]QueueMessage, error) {
+	q := url.Values{"peekonly": {"true"}, "numofmessages": {strconv.Itoa(maxMessages)}}
+	var res messageList
+	if err := c.getXML(serviceQueue, messagesPath(queue), q, &res); err != nil {
+		return nil, err
+	}
+	return res.messages(), nil
+}
+
+// getMessages dequeues up to n messages, hiding them for visibility.
+func (c *Client) getMessages(queue string, n int, visibility time.Duration) ([]QueueMessage, error) {
+	q := url.Values{
+		"numofmessages":     {strconv.Itoa(n)},
+		"visibilitytimeout": {strconv.Itoa(int(visibility / time.Second))},
+	}
+	var res messageList
+	if err := c.getXML(serviceQueue, messagesPath(queue), q, &res); err != nil {
+		return nil, err
+	}
+	return res.messages(), nil
+}
+
+func messageBody(text string) ([]byte, error) {
+	body, err := xml.Marshal(struct {
+		XMLName     xml.Name `xml:"QueueMessage"`
+		MessageText string   `xml:"MessageText"`
+	}{MessageText: text})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// PutMessage adds a message to the back of a queue. text is sent as is (see
+// EncodeMessage). It becomes visible after visibility and expires after ttl;
+// a negative ttl means it never expires and zero uses the service default of
+// seven days.
+func (c *Client) PutMessage(queue, text string, visibility, ttl time.Duration) error {
+	q := url.Values{}
+	if visibility > 0 {
+		q.Set("visibilitytimeout", strconv.Itoa(int(visibility/time.Second)))
+	}
+	if ttl < 0 {
+		q.Set("messagettl", "-1")
+	} else if ttl > 0 {
+		q.Set("messagettl", strconv.Itoa(int(ttl/time.Second)))
+	}
+	body, err := messageBody(text)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(serviceQueue, http.MethodPost, messagesPath(queue), q, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	return c.send(serviceQueue, req)
+}
+
+// DequeueMessage removes the message at the front of the queue and returns it.
+func (c *Client) DequeueMessage(queue string) (QueueMessage, error) {
+	msgs, err := c.getMessages(queue, 1, releaseTimeout)
+	if err != nil {
+		return QueueMessage{}, err
+	}
+	if len(msgs) == 0 {
+		return QueueMessage{}, fmt.Errorf("queue %s has no visible messages", queue)
+	}
+	return msgs[0], c.deleteMessage(queue, msgs[0].ID, msgs[0].PopReceipt)
+}
+
+// UpdateMessage replaces the text of the message with the given ID, which
+// must be at the front of the queue, and makes it visible again immediately.
+// Its dequeue count rises by one, as it is dequeued for its pop receipt.
+func (c *Client) UpdateMessage(queue, id, text string) error {
+	return c.withMessage(queue, id, func(m QueueMessage) error {
+		return c.updateMessage(queue, m.ID, m.PopReceipt, &text, 0)
+	})
+}
+
+// DeleteMessage removes the message with the given ID, which must be at the
+// front of the queue.
+func (c *Client) DeleteMessage(queue, id string) error {
+	return c.withMessage(queue, id, func(m QueueMessage) error {
+		return c.deleteMessage(queue, m.ID, m.PopReceipt)
+	})
+}
+
+// ClearMessages deletes every message in a queue.
+func (c *Client) ClearMessages(queue string) error {
+	req, err := c.newRequest(serviceQueue, http.MethodDelete, messagesPath(queue), nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceQueue, req)
+}
+
+// withMessage dequeues the message at the front of the queue and calls fn
+// with it if it has the given ID. The service only hands out pop receipts on
+// dequeue, and dequeuing any other message would raise its dequeue count
+// (sending it towards a poison queue), so only the front message is touched.
+// A different front message is made visible again straight away.
+func (c *Client) withMessage(queue, id string, fn func(QueueMessage) error) error {
+	msgs, err := c.getMessages(queue, 1, releaseTimeout)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("queue %s has no visible messages", queue)
+	}
+	if m := msgs[0]; m.ID != id {
+		err := fmt.Errorf("message %s is not at the front of queue %s; only the front message can be changed", id, queue)
+		if rerr := c.updateMessage(queue, m.ID, m.PopReceipt, nil, 0); rerr != nil {
+			return errors.Join(err, fmt.Errorf("releasing message %s: %w", m.ID, rerr))
+		}
+		return err
+	}
+	return fn(msgs[0])
+}
+
+// updateMessage changes the visibility and, when text is not nil, the content of a dequeued message.
+func (c *Client) updateMessage(queue, id, popReceipt string, text *string, visibility time.Duration) error {
+	q := url.Values{
+		"popreceipt":        {popReceipt},
+		"visibilitytimeout": {strconv.Itoa(int(visibility / time.Second))},
+	}
+	var body []byte
+	if text != nil {
+		var err error
+		if body, err = messageBody(*text); err != nil {
+			return err
+		}
+	}
+	req, err := c.newRequest(serviceQueue, http.MethodPut, messagesPath(queue)+"/"+url.PathEscape(id), q, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if text != nil {
+		req.Header.Set("Content-Type", "application/xml")
+	}
+	return c.send(serviceQueue, req)
+}
+
+func (c *Client) deleteMessage(queue, id, popReceipt string) error {
+	q := url.Values{"popreceipt": {popReceipt}}
+	req, err := c.newRequest(serviceQueue, http.MethodDelete, messagesPath(queue)+"/"+url.PathEscape(id), q, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceQueue, req)
+}
+
+// CreateQueue creates a queue with the given metadata.
+func (c *Client) CreateQueue(name string, metadata map[string]string) error {
+	req, err := c.newRequest(serviceQueue, http.MethodPut, "/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	setMetadata(req, metadata)
+	return c.send(serviceQueue, req)
+}
+
+// DeleteQueue deletes a queue and its messages.
+func (c *Client) DeleteQueue(name string) error {
+	req, err := c.newRequest(serviceQueue, http.MethodDelete, "/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceQueue, req)
+}
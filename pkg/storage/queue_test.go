@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDecodeMessage(t *testing.T) {
+	tests := []struct {
+		text string
+		body string
+		enc  MessageEncoding
+	}{
+		{"", "", EncodingText},
+		{"aGVsbG8=", "hello", EncodingBase64},
+		{"eyJpZCI6IDF9", `{"id": 1}`, EncodingBase64},
+		{"bGluZQpuZXh0", "line\nnext", EncodingBase64},
+		{"hello", "hello", EncodingText},
+		{"test", "test", EncodingText}, // valid base64, but decodes to binary
+		{"AAEC", "AAEC", EncodingText}, // control characters
+		{"/w==", "/w==", EncodingText}, // not UTF-8
+		{"aGVsbG8", "aGVsbG8", EncodingText},
+		{`{"id": 1}`, `{"id": 1}`, EncodingText},
+	}
+	for _, tt := range tests {
+		body, enc := DecodeMessage(tt.text)
+		if body != tt.body || enc != tt.enc {
+			t.Errorf("DecodeMessage(%q) = %q, %v; want %q, %v", tt.text, body, enc, tt.body, tt.enc)
+		}
+	}
+}
+
+func TestEncodeMessageRoundTrip(t *testing.T) {
+	for _, body := range []string{"hello", `{"order": 42}`, "naïve ✓"} {
+		body2, enc := DecodeMessage(EncodeMessage(body, EncodingBase64))
+		if body2 != body || enc != EncodingBase64 {
+			t.Errorf("base64 round trip of %q = %q, %v", body, body2, enc)
+		}
+		if text := EncodeMessage(body, EncodingText); text != body {
+			t.Errorf("EncodeMessage(%q, text) = %q", body, text)
+		}
+	}
+}
+
+func TestParseMessageEncoding(t *testing.T) {
+	for in, want := range map[string]MessageEncoding{"base64": EncodingBase64, "B64": EncodingBase64, "text": EncodingText, "raw": EncodingText} {
+		if got, err := ParseMessageEncoding(in); err != nil || got != want {
+			t.Errorf("ParseMessageEncoding(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseMessageEncoding("utf8"); err == nil {
+		t.Error("ParseMessageEncoding(utf8) succeeded")
+	}
+}
+
+// fakeQueue serves Get Messages with a single front message and records the
+// other requests made to it.
+type fakeQueue struct {
+	mu       sync.Mutex
+	front    string
+	requests []string
+}
+
+func (f *fakeQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+	if r.Method == http.MethodGet {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><QueueMessagesList><QueueMessage>`+
+			`<MessageId>%s</MessageId><PopReceipt>receipt-%s</PopReceipt><DequeueCount>1</DequeueCount>`+
+			`<MessageText>aGk=</MessageText></QueueMessage></QueueMessagesList>`, f.front, f.front)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newFakeQueueClient(t *testing.T, front string) (*Client, *fakeQueue) {
+	fake := &fakeQueue{front: front}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	account := DevelopmentAccount()
+	account.QueueEndpoint = srv.URL + "/" + account.Name
+	return NewClient(account), fake
+}
+
+func TestDeleteMessageFront(t *testing.T) {
+	c, fake := newFakeQueueClient(t, "m1")
+	if err := c.DeleteMessage("jobs", "m1"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /devstoreaccount1/jobs/messages?numofmessages=1&visibilitytimeout=30",
+		"DELETE /devstoreaccount1/jobs/messages/m1?popreceipt=receipt-m1",
+	}
+	if strings.Join(fake.requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant\n%s", strings.Join(fake.requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUpdateMessageNotAtFront(t *testing.T) {
+	c, fake := newFakeQueueClient(t, "m1")
+	err := c.UpdateMessage("jobs", "m2", "new")
+	if err == nil || !strings.Contains(err.Error(), "not at the front") {
+		t.Fatalf("error = %v, want one about the front message", err)
+	}
+	// Only the front message is dequeued, and it is released again at once
+	want := []string{
+		"GET /devstoreaccount1/jobs/messages?numofmessages=1&visibilitytimeout=30",
+		"PUT /devstoreaccount1/jobs/messages/m1?popreceipt=receipt-m1&visibilitytimeout=0",
+	}
+	if strings.Join(fake.requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant\n%s", strings.Join(fake.requests, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AzuriteServices lists the services whose data ResetAzuriteData can remove.
+var AzuriteServices = []string{"blob", "queue", "table"}
+
+// serviceData names the entries of Azurite's data directory that hold each
+// service's metadata databases and stored content.
+var serviceData = map[string][]string{
+	"blob":  {"__blobstorage__", "__azurite_db_blob__.json", "__azurite_db_blob_extent__.json"},
+	"queue": {"__queuestorage__", "__azurite_db_queue__.json", "__azurite_db_queue_extent__.json"},
+	"table": {"__azurite_db_table__.json"},
+}
+
+// ResetAzuriteData stops the instance, writes a gzipped tar of its data to
+// the file snapshot unless it is "", deletes the data of services (every
+// service when empty) and starts it again. Progress messages go to logf.
+func ResetAzuriteData(cfg AzuriteConfig, services []string, snapshot string, logf func(string)) error {
+	if len(services) == 0 {
+		services = AzuriteServices
+	}
+	var names []string
+	for _, s := range services {
+		entries, ok := serviceData[s]
+		if !ok {
+			return fmt.Errorf("unknown service %q (want blob, queue or table)", s)
+		}
+		names = append(names, entries...)
+	}
+	rt, err := RuntimeFor(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.InMemory && snapshot != "" {
+		return fmt.Errorf("%s keeps its data in memory; there is nothing to snapshot", cfg.Name)
+	}
+
+	if _, state, err := rt.State(cfg); err != nil {
+		return err
+	} else if state == "running" || state == "paused" {
+		logf(fmt.Sprintf("Stopping %s...\n", cfg.Name))
+		if err := rt.Stop(cfg); err != nil {
+			return err
+		}
+	}
+
+	// In-memory data went away with the process
+	if !cfg.InMemory {
+		if snapshot != "" {
+			logf(fmt.Sprintf("Writing snapshot to %s...\n", snapshot))
+			if err := exportTo(rt, cfg, snapshot); err != nil {
+				return fmt.Errorf("snapshot: %w", err)
+			}
+		}
+		logf(fmt.Sprintf("Removing %s data of %s...\n", strings.Join(services, ", "), cfg.Name))
+		if err := rt.RemoveData(cfg, names); err != nil {
+			return err
+		}
+	}
+
+	_, err = EnsureAzurite(cfg, logf)
+	return err
+}
+
+func exportTo(rt Runtime, cfg AzuriteConfig, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := rt.ExportData(cfg, f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// writeTarGz writes the contents of dir to w as a gzipped tar.
+func writeTarGz(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteTarGz(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "__blobstorage__"), 0o755)
+	os.WriteFile(filepath.Join(dir, "__blobstorage__", "extent"), []byte("blob data"), 0o644)
+	os.WriteFile(filepath.Join(dir, "__azurite_db_table__.json"), []byte("{}"), 0o644)
+
+	var buf bytes.Buffer
+	if err := writeTarGz(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		if hdr.Typeflag == tar.TypeDir {
+			data = []byte("<dir>")
+		}
+		got[hdr.Name] = string(data)
+	}
+	want := map[string]string{
+		"__azurite_db_table__.json": "{}",
+		"__blobstorage__":           "<dir>",
+		"__blobstorage__/extent":    "blob data",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("archive = %v, want %v", got, want)
+	}
+}
+
+func TestResetAzuriteDataUnknownService(t *testing.T) {
+	err := ResetAzuriteData(DefaultAzuriteConfig(), []string{"blob", "file"}, "", func(string) {})
+	if err == nil || err.Error() != `unknown service "file" (want blob, queue or table)` {
+		t.Errorf("ResetAzuriteData = %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Runtime runs Azurite instances: as containers with docker or podman, or as
+// a local azurite process.
+type Runtime interface {
+	Name() string
+
+	// ID returns the container or process ID of the instance, "" if there is none.
+	ID(cfg AzuriteConfig) string
+
+	// Ensure creates or starts the instance and returns its ID.
+	Ensure(cfg AzuriteConfig, logf func(string)) (string, error)
+
+	// State returns the ID and state of the instance; id is "" when it does not exist.
+	State(cfg AzuriteConfig) (id, state string, err error)
+
+	Stop(cfg AzuriteConfig) error
+
+	// Pause freezes a running instance and Unpause resumes it.
+	Pause(cfg AzuriteConfig) error
+	Unpause(cfg AzuriteConfig) error
+
+	// Remove stops the instance and forgets it, keeping its data, so that the
+	// next Ensure creates it afresh with the current options.
+	Remove(cfg AzuriteConfig) error
+
+	// Pull fetches the newest image for the instance; output goes to logf.
+	Pull(cfg AzuriteConfig, logf func(string)) error
+
+	// ExportData writes the instance's data directory to w as a gzipped tar,
+	// and RemoveData deletes the given entries of it. The instance must be
+	// stopped for either.
+	ExportData(cfg AzuriteConfig, w io.Writer) error
+	RemoveData(cfg AzuriteConfig, names []string) error
+
+	// Version describes what runs the instance: the image of its container or
+	// the version of the azurite binary.
+	Version(cfg AzuriteConfig) string
+
+	// Logs copies the instance's output to w, then waits for more while
+	// follow is set, until the instance ends or ctx is done.
+	Logs(ctx context.Context, cfg AzuriteConfig, follow bool, w io.Writer) error
+}
+
+// Runtimes lists the runtime names in the order they are auto-detected.
+var Runtimes = []string{"docker", "podman", "azurite"}
+
+// RuntimeFor returns the runtime named by cfg.Runtime, or the first of
+// Runtimes found on PATH when it is empty.
+func RuntimeFor(cfg AzuriteConfig) (Runtime, error) {
+	if cfg.Runtime != "" {
+		return runtimeNamed(cfg.Runtime)
+	}
+	for _, name := range Runtimes {
+		if _, err := exec.LookPath(name); err == nil {
+			return runtimeNamed(name)
+		}
+	}
+	return nil, fmt.Errorf("no Azurite runtime found: install one of %s", strings.Join(Runtimes, ", "))
+}
+
+func runtimeNamed(name string) (Runtime, error) {
+	switch name {
+	case "docker", "podman":
+		return containerRuntime{bin: name}, nil
+	case "azurite":
+		return nativeRuntime{}, nil
+	}
+	return nil, fmt.Errorf("unknown runtime %q (want %s)", name, strings.Join(Runtimes, ", "))
+}
+
+// --- Containers ---
+
+// containerRuntime drives docker or podman, whose command lines agree for
+// everything used here.
+type containerRuntime struct {
+	bin string
+}
+
+func (r containerRuntime) Name() string { return r.bin }
+
+func (r containerRuntime) ID(cfg AzuriteConfig) string {
+	// The name filter matches substrings, so anchor it to the whole name
+	filter := "name=^/?" + regexp.QuoteMeta(cfg.ContainerName) + "$"
+	out, err := exec.Command(r.bin, "ps", "-aq", "--filter", filter).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r containerRuntime) Ensure(cfg AzuriteConfig, logf func(string)) (string, error) {
+	id := r.ID(cfg)
+	if id == "" {
+		// No container exists: create it with the configured ports and volume
+		args, err := cfg.dockerRunArgs()
+		if err != nil {
+			return "", err
+		}
+		cmd := exec.Command(r.bin, args...)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(out.String()))
+		}
+		id = strings.TrimSpace(out.String())
+		logf(fmt.Sprintf("Azurite container started: %s\n", id))
+		return id, nil
+	}
+
+	// Container exists: start if stopped
+	status, _ := exec.Command(r.bin, "inspect", "-f", "{{.State.Running}}", id).Output()
+	if strings.TrimSpace(string(status)) != "true" {
+		if out, err := exec.Command(r.bin, "start", id).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+		}
+		logf(fmt.Sprintf("Started existing Azurite container: %s\n", id))
+	}
+	logf(fmt.Sprintf("Using existing Azurite container: %s\n", id))
+	return id, nil
+}
+
+func (r containerRuntime) State(cfg AzuriteConfig) (id, state string, err error) {
+	id = r.ID(cfg)
+	if id == "" {
+		return "", "", nil
+	}
+	out, err := exec.Command(r.bin, "inspect", "-f", "{{.State.Status}}", id).Output()
+	if err != nil {
+		return id, "", err
+	}
+	return id, strings.TrimSpace(string(out)), nil
+}
+
+// run executes one command on the instance's container.
+func (r containerRuntime) run(cfg AzuriteConfig, command ...string) error {
+	id := r.ID(cfg)
+	if id == "" {
+		return fmt.Errorf("no %s container", cfg.ContainerName)
+	}
+	args := append(command, id)
+	if out, err := exec.Command(r.bin, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (r containerRuntime) Stop(cfg AzuriteConfig) error    { return r.run(cfg, "stop") }
+func (r containerRuntime) Pause(cfg AzuriteConfig) error   { return r.run(cfg, "pause") }
+func (r containerRuntime) Unpause(cfg AzuriteConfig) error { return r.run(cfg, "unpause") }
+func (r containerRuntime) Remove(cfg AzuriteConfig) error  { return r.run(cfg, "rm", "-f") }
+
+func (r containerRuntime) Pull(cfg AzuriteConfig, logf func(string)) error {
+	cmd := exec.Command(r.bin, "pull", cfg.ImageRef())
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	lines := make(chan string)
+	go func() {
+		copyToChan(context.Background(), out, lines)
+		close(lines)
+	}()
+	for line := range lines {
+		logf(line)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// withData runs a throwaway container of the instance's image with its data
+// volume mounted at /data, running command instead of azurite.
+func (r containerRuntime) withData(cfg AzuriteConfig, stdout io.Writer, command string, args ...string) error {
+	volume, err := mountSource(cfg.Volume)
+	if err != nil {
+		return err
+	}
+	run := append([]string{"run", "--rm", "-v", volume + ":/data", "--entrypoint", command, cfg.ImageRef()}, args...)
+	cmd := exec.Command(r.bin, run...)
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (r containerRuntime) ExportData(cfg AzuriteConfig, w io.Writer) error {
+	return r.withData(cfg, w, "tar", "-czf", "-", "-C", "/data", ".")
+}
+
+func (r containerRuntime) RemoveData(cfg AzuriteConfig, names []string) error {
+	args := []string{"-rf"}
+	for _, name := range names {
+		args = append(args, "/data/"+name)
+	}
+	return r.withData(cfg, io.Discard, "rm", args...)
+}
+
+func (r containerRuntime) Version(cfg AzuriteConfig) string {
+	id := r.ID(cfg)
+	if id == "" {
+		return cfg.ImageRef()
+	}
+	out, err := exec.Command(r.bin, "inspect", "-f", "{{.Config.Image}}", id).Output()
+	if err != nil {
+		return cfg.ImageRef()
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r containerRuntime) Logs(ctx context.Context, cfg AzuriteConfig, follow bool, w io.Writer) error {
+	id := r.ID(cfg)
+	if id == "" {
+		return fmt.Errorf("no %s container", cfg.ContainerName)
+	}
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "-f")
+	}
+	cmd := exec.CommandContext(ctx, r.bin, append(args, id)...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
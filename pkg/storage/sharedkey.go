@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// signRequest adds a SharedKey or SharedKeyLite Authorization header to req,
+// depending on account.Auth. The table service has its own string-to-sign
+// for both schemes; blob, queue and file share one.
+func signRequest(req *http.Request, svc service, account Account) {
+	var s string
+	switch {
+	case account.Auth == SharedKeyLite && svc == serviceTable:
+		s = tableLiteStringToSign(req, account.Name)
+	case account.Auth == SharedKeyLite:
+		s = blobLiteStringToSign(req, account.Name)
+	case svc == serviceTable:
+		s = tableStringToSign(req, account.Name)
+	default:
+		s = blobStringToSign(req, account.Name)
+	}
+	req.Header.Set("Authorization", account.Auth.String()+" "+account.Name+":"+computeSignature(account.Key, s))
+}
+
+// computeSignature returns the base64 HMAC-SHA256 of stringToSign.
+func computeSignature(key []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// blobStringToSign builds the SharedKey string-to-sign used by the blob, queue and file services.
+func blobStringToSign(req *http.Request, accountName string) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalizedHeaders(req) + canonicalizedResource(req.URL, accountName),
+	}, "\n")
+}
+
+// blobLiteStringToSign builds the SharedKeyLite string-to-sign used by the blob, queue and file services.
+func blobLiteStringToSign(req *http.Request, accountName string) string {
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		canonicalizedHeaders(req) + canonicalizedCompResource(req.URL, accountName),
+	}, "\n")
+}
+
+// tableStringToSign builds the SharedKey string-to-sign used by the table service.
+func tableStringToSign(req *http.Request, accountName string) string {
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		tableDate(req),
+		canonicalizedCompResource(req.URL, accountName),
+	}, "\n")
+}
+
+// tableLiteStringToSign builds the SharedKeyLite string-to-sign used by the table service.
+func tableLiteStringToSign(req *http.Request, accountName string) string {
+	return tableDate(req) + "\n" + canonicalizedCompResource(req.URL, accountName)
+}
+
+// tableDate is x-ms-date when present, else Date; the table service signs whichever is sent.
+func tableDate(req *http.Request) string {
+	if date := req.Header.Get("x-ms-date"); date != "" {
+		return date
+	}
+	return req.Header.Get("Date")
+}
+
+// canonicalizedHeaders returns the sorted x-ms-* headers, one "name:value\n" per header.
+// The header map is read directly, as metadata names are not canonicalized
+// (see setMetadata) and Header.Values would miss them.
+func canonicalizedHeaders(req *http.Request) string {
+	headers := map[string][]string{}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, "x-ms-") {
+			continue
+		}
+		// Copy rather than trim in place: the slices belong to the request
+		for _, v := range values {
+			headers[lower] = append(headers[lower], strings.TrimSpace(v))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.Join(headers[name], ",") + "\n")
+	}
+	return b.String()
+}
+
+// canonicalizedResource returns "/account/path" followed by every query parameter,
+// sorted by name, as "\nname:value1,value2".
+func canonicalizedResource(u *url.URL, accountName string) string {
+	var b strings.Builder
+	b.WriteString("/" + accountName + resourcePath(u))
+
+	query := map[string][]string{}
+	for name, values := range u.Query() {
+		lower := strings.ToLower(name)
+		query[lower] = append(query[lower], values...)
+	}
+	var names []string
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		b.WriteString("\n" + name + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+// canonicalizedCompResource returns "/account/path", keeping only the comp query parameter.
+func canonicalizedCompResource(u *url.URL, accountName string) string {
+	s := "/" + accountName + resourcePath(u)
+	if comp := u.Query().Get("comp"); comp != "" {
+		s += "?comp=" + comp
+	}
+	return s
+}
+
+func resourcePath(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	return p
+}
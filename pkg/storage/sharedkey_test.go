@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"net/http"
+	"testing"
+)
+
+const testDate = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// The signatures below were computed independently of this package with
+// openssl dgst -sha256 -mac HMAC over the expected string-to-sign, keyed with
+// the development account key.
+func TestSignRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		account  string
+		svc      service
+		auth     AuthScheme
+		method   string
+		url      string
+		header   map[string]string
+		length   int64
+		toSign   string
+		wantAuth string
+	}{
+		{
+			name:    "blob SharedKey list blobs",
+			account: "devstoreaccount1",
+			svc:     serviceBlob,
+			auth:    SharedKey,
+			method:  "GET",
+			url:     "http://127.0.0.1:10000/devstoreaccount1/photos?restype=container&comp=list",
+			header:  map[string]string{"x-ms-date": testDate, "x-ms-version": "2021-12-02"},
+			toSign: "GET\n\n\n\n\n\n\n\n\n\n\n\n" +
+				"x-ms-date:" + testDate + "\nx-ms-version:2021-12-02\n" +
+				"/devstoreaccount1/devstoreaccount1/photos\ncomp:list\nrestype:container",
+			wantAuth: "SharedKey devstoreaccount1:9AuvcqplUTiAI/7kA9/QXPjqv2j41iDVN0aaU1LTJWg=",
+		},
+		{
+			name:    "queue SharedKey put message",
+			account: "devstoreaccount1",
+			svc:     serviceQueue,
+			auth:    SharedKey,
+			method:  "POST",
+			url:     "http://127.0.0.1:10001/devstoreaccount1/jobs/messages?visibilitytimeout=10",
+			header:  map[string]string{"Content-Type": "application/xml", "x-ms-date": testDate, "x-ms-version": "2021-12-02"},
+			length:  42,
+			toSign: "POST\n\n\n42\n\napplication/xml\n\n\n\n\n\n\n" +
+				"x-ms-date:" + testDate + "\nx-ms-version:2021-12-02\n" +
+				"/devstoreaccount1/devstoreaccount1/jobs/messages\nvisibilitytimeout:10",
+			wantAuth: "SharedKey devstoreaccount1:K5Fs8MiD/hlLUrBjSPuSy4ccJsoHEJIis5eqeuA5Cn4=",
+		},
+		{
+			name:    "file SharedKey range and metadata",
+			account: "acct",
+			svc:     serviceFile,
+			auth:    SharedKey,
+			method:  "HEAD",
+			url:     "https://acct.file.core.windows.net/share/dir/file.txt",
+			header:  map[string]string{"Range": "bytes=0-99", "x-ms-date": testDate, "x-ms-version": "2021-12-02", "x-ms-meta-owner": "  a b  "},
+			toSign: "HEAD\n\n\n\n\n\n\n\n\n\n\nbytes=0-99\n" +
+				"x-ms-date:" + testDate + "\nx-ms-meta-owner:a b\nx-ms-version:2021-12-02\n" +
+				"/acct/share/dir/file.txt",
+			wantAuth: "SharedKey acct:aPL0izeOWIkercQZ/vZIpMQgVRv4efEOF1FGWHPZg+E=",
+		},
+		{
+			name:     "table SharedKey query tables",
+			account:  "devstoreaccount1",
+			svc:      serviceTable,
+			auth:     SharedKey,
+			method:   "GET",
+			url:      "http://127.0.0.1:10002/devstoreaccount1/Tables?$top=5",
+			header:   map[string]string{"Content-Type": "application/json", "x-ms-date": testDate},
+			toSign:   "GET\n\napplication/json\n" + testDate + "\n/devstoreaccount1/devstoreaccount1/Tables",
+			wantAuth: "SharedKey devstoreaccount1:N7CQuk330wUJhTAHytgH+SmIgrQ4bDM8H8yQ0pXzchk=",
+		},
+		{
+			name:    "blob SharedKeyLite keeps only comp",
+			account: "devstoreaccount1",
+			svc:     serviceBlob,
+			auth:    SharedKeyLite,
+			method:  "GET",
+			url:     "http://127.0.0.1:10000/devstoreaccount1/photos?restype=container&comp=list",
+			header:  map[string]string{"x-ms-date": testDate, "x-ms-version": "2021-12-02"},
+			toSign: "GET\n\n\n\n" +
+				"x-ms-date:" + testDate + "\nx-ms-version:2021-12-02\n" +
+				"/devstoreaccount1/devstoreaccount1/photos?comp=list",
+			wantAuth: "SharedKeyLite devstoreaccount1:XLrEvKSpigmPX5xZiUkKUY53PqxpLx3Y6Mg29ZDz/ww=",
+		},
+		{
+			name:     "table SharedKeyLite entity",
+			account:  "devstoreaccount1",
+			svc:      serviceTable,
+			auth:     SharedKeyLite,
+			method:   "DELETE",
+			url:      "http://127.0.0.1:10002/devstoreaccount1/people(PartitionKey='p',RowKey='r')",
+			header:   map[string]string{"x-ms-date": testDate},
+			toSign:   testDate + "\n/devstoreaccount1/devstoreaccount1/people(PartitionKey='p',RowKey='r')",
+			wantAuth: "SharedKeyLite devstoreaccount1:V9kovbcokY9qjdlGESN2bgQ/h8uB9iJOu9AYeDvg7k0=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			req.ContentLength = tt.length
+
+			account := DevelopmentAccount()
+			account.Name = tt.account
+			account.Auth = tt.auth
+
+			var toSign string
+			switch {
+			case tt.auth == SharedKeyLite && tt.svc == serviceTable:
+				toSign = tableLiteStringToSign(req, account.Name)
+			case tt.auth == SharedKeyLite:
+				toSign = blobLiteStringToSign(req, account.Name)
+			case tt.svc == serviceTable:
+				toSign = tableStringToSign(req, account.Name)
+			default:
+				toSign = blobStringToSign(req, account.Name)
+			}
+			if toSign != tt.toSign {
+				t.Errorf("string to sign:\n%q\nwant\n%q", toSign, tt.toSign)
+			}
+
+			signRequest(req, tt.svc, account)
+			if got := req.Header.Get("Authorization"); got != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestCanonicalizedHeadersLeavesRequestAlone(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:10000/devstoreaccount1", nil)
+	req.Header.Add("x-ms-meta-Tag", " one ")
+	req.Header.Add("x-ms-meta-Tag", "two ")
+
+	if got, want := canonicalizedHeaders(req), "x-ms-meta-tag:one,two\n"; got != want {
+		t.Errorf("canonicalizedHeaders = %q, want %q", got, want)
+	}
+	if got := req.Header.Values("x-ms-meta-Tag"); got[0] != " one " || got[1] != "two " {
+		t.Errorf("header values changed to %q", got)
+	}
+}
+
+func TestCanonicalizedResource(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://h", "/acct/"},
+		{"http://h/acct", "/acct/acct"},
+		{"http://h/acct/c?B=2&a=1", "/acct/acct/c\na:1\nb:2"},
+		{"http://h/acct/c?a=z&a=y", "/acct/acct/c\na:y,z"},
+		{"http://h/acct/a%20b", "/acct/acct/a%20b"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		if got := canonicalizedResource(req.URL, "acct"); got != tt.want {
+			t.Errorf("canonicalizedResource(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
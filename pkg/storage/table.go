@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type tableList struct {
+	Value []struct {
+		TableName string `json:"TableName"`
+	} `json:"value"`
+}
+
+type entityList struct {
+	Value []map[string]interface{} `json:"value"`
+}
+
+// ListTables implements Backend.
+func (c *Client) ListTables() ([]string, error) {
+	var names []string
+	q := url.Values{}
+	for {
+		var res tableList
+		header, err := c.getJSON(serviceTable, "/Tables", q, &res)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range res.Value {
+			names = append(names, t.TableName)
+		}
+		next := header.Get("x-ms-continuation-NextTableName")
+		if next == "" {
+			return names, nil
+		}
+		q.Set("NextTableName", next)
+	}
+}
+
+// TableQuery narrows an entity query. Filter is an OData $filter expression
+// and Select a comma-separated $select projection; empty means everything.
+type TableQuery struct {
+	Filter string
+	Select string
+}
+
+// QueryEntities returns one page of entities in a table, each named
+// "PartitionKey/RowKey" with the full Entity attached. token is the Next of
+// the previous page, which carries the NextPartitionKey and NextRowKey
+// continuation headers.
+func (c *Client) QueryEntities(table string, query TableQuery, token string) (Page, error) {
+	q, err := url.ParseQuery(token)
+	if err != nil {
+		return Page{}, fmt.Errorf("invalid continuation token: %w", err)
+	}
+	q.Set("$top", strconv.Itoa(PageSize))
+	if query.Filter != "" {
+		q.Set("$filter", query.Filter)
+	}
+	if query.Select != "" {
+		q.Set("$select", query.Select)
+	}
+
+	req, err := c.newRequest(serviceTable, http.MethodGet, "/"+url.PathEscape(table)+"()", q, nil)
+	if err != nil {
+		return Page{}, err
+	}
+	// Minimal metadata adds the @odata.type annotations needed to tell Int64,
+	// DateTime, Guid and Binary apart from plain strings.
+	req.Header.Set("Accept", "application/json;odata=minimalmetadata")
+	resp, err := c.do(serviceTable, req)
+	if err != nil {
+		return Page{}, err
+	}
+	defer resp.Body.Close()
+
+	var res entityList
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&res); err != nil {
+		return Page{}, err
+	}
+	var page Page
+	for _, raw := range res.Value {
+		e := parseEntity(raw)
+		name := e.PartitionKey + "/" + e.RowKey
+		page.Entries = append(page.Entries, Entry{Name: name, Path: name, Entity: &e})
+	}
+	page.Next = tableContinuation(resp.Header)
+	return page, nil
+}
+
+// tableContinuation packs the x-ms-continuation-* entity headers into a
+// token that QueryEntities can turn back into query parameters.
+func tableContinuation(header http.Header) string {
+	next := url.Values{}
+	if pk := header.Get("x-ms-continuation-NextPartitionKey"); pk != "" {
+		next.Set("NextPartitionKey", pk)
+	}
+	if rk := header.Get("x-ms-continuation-NextRowKey"); rk != "" {
+		next.Set("NextRowKey", rk)
+	}
+	return next.Encode()
+}
+
+// CreateTable creates a table.
+func (c *Client) CreateTable(name string) error {
+	body, err := json.Marshal(map[string]string{"TableName": name})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(serviceTable, http.MethodPost, "/Tables", nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Prefer", "return-no-content")
+	return c.send(serviceTable, req)
+}
+
+// DeleteTable deletes a table and its entities.
+func (c *Client) DeleteTable(name string) error {
+	path := "/Tables('" + url.PathEscape(strings.ReplaceAll(name, "'", "''")) + "')"
+	req, err := c.newRequest(serviceTable, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(serviceTable, req)
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestListTablesFollowsContinuation(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.URL.Query().Get("NextTableName") == "" {
+			w.Header().Set("x-ms-continuation-NextTableName", "m")
+			fmt.Fprint(w, `{"value":[{"TableName":"a"},{"TableName":"b"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":[{"TableName":"m"}]}`)
+	})
+	names, err := c.ListTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "m"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListTables = %v, want %v", names, want)
+	}
+	if got, want := fake.requests[1].URL, "/devstoreaccount1/Tables?NextTableName=m"; got != want {
+		t.Errorf("second request %s, want %s", got, want)
+	}
+}
+
+func TestQueryEntitiesRequest(t *testing.T) {
+	tests := []struct {
+		query TableQuery
+		token string
+		want  url.Values
+	}{
+		{TableQuery{}, "", url.Values{"$top": {strconv.Itoa(PageSize)}}},
+		{
+			TableQuery{Filter: "Age gt 30", Select: "Name,Age"}, "",
+			url.Values{"$top": {strconv.Itoa(PageSize)}, "$filter": {"Age gt 30"}, "$select": {"Name,Age"}},
+		},
+		{
+			TableQuery{}, "NextPartitionKey=1%211%21cA--&NextRowKey=1%211%21cg--",
+			url.Values{"$top": {strconv.Itoa(PageSize)}, "NextPartitionKey": {"1!1!cA--"}, "NextRowKey": {"1!1!cg--"}},
+		},
+	}
+	for _, tt := range tests {
+		c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+			fmt.Fprint(w, `{"value":[]}`)
+		})
+		if _, err := c.QueryEntities("people", tt.query, tt.token); err != nil {
+			t.Fatal(err)
+		}
+		r := fake.requests[0]
+		u, _ := url.Parse(r.URL)
+		if u.Path != "/devstoreaccount1/people()" {
+			t.Errorf("path = %s", u.Path)
+		}
+		if got := u.Query(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("query = %v, want %v", got, tt.want)
+		}
+		if got, want := r.Header.Get("Accept"), "application/json;odata=minimalmetadata"; got != want {
+			t.Errorf("Accept = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestQueryEntitiesPage(t *testing.T) {
+	c, _ := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		w.Header().Set("x-ms-continuation-NextPartitionKey", "1!1!cA--")
+		w.Header().Set("x-ms-continuation-NextRowKey", "1!1!cg--")
+		fmt.Fprint(w, `{"value":[{"PartitionKey":"p","RowKey":"r","Big":"5","Big@odata.type":"Edm.Int64"}]}`)
+	})
+	page, err := c.QueryEntities("people", TableQuery{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Name != "p/r" || page.Entries[0].Path != "p/r" {
+		t.Fatalf("entries = %+v", page.Entries)
+	}
+	if got := page.Entries[0].Entity.Properties["Big"]; got != (Property{EdmInt64, "5"}) {
+		t.Errorf("Big = %v", got)
+	}
+	if want := "NextPartitionKey=1%211%21cA--&NextRowKey=1%211%21cg--"; page.Next != want {
+		t.Errorf("Next = %q, want %q", page.Next, want)
+	}
+}
+
+func TestQueryEntitiesBadToken(t *testing.T) {
+	c := NewClient(DevelopmentAccount())
+	if _, err := c.QueryEntities("people", TableQuery{}, "%zz"); err == nil {
+		t.Error("QueryEntities with a malformed token succeeded")
+	}
+}
+
+func TestTableContinuation(t *testing.T) {
+	tests := []struct {
+		pk, rk string
+		want   string
+	}{
+		{"", "", ""},
+		{"a", "", "NextPartitionKey=a"},
+		{"a b", "c&d", "NextPartitionKey=a+b&NextRowKey=c%26d"},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.pk != "" {
+			h.Set("x-ms-continuation-NextPartitionKey", tt.pk)
+		}
+		if tt.rk != "" {
+			h.Set("x-ms-continuation-NextRowKey", tt.rk)
+		}
+		if got := tableContinuation(h); got != tt.want {
+			t.Errorf("tableContinuation(%q, %q) = %q, want %q", tt.pk, tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestCreateAndDeleteTableRequests(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	if err := c.CreateTable("people"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTable("o'neil"); err != nil {
+		t.Fatal(err)
+	}
+	create, del := fake.requests[0], fake.requests[1]
+	if got, want := create.Method+" "+create.URL+" "+string(create.Body), `POST /devstoreaccount1/Tables {"TableName":"people"}`; got != want {
+		t.Errorf("create = %s, want %s", got, want)
+	}
+	if got, want := del.Method+" "+del.URL, "DELETE /devstoreaccount1/Tables('o%27%27neil')"; got != want {
+		t.Errorf("delete = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Environment variables read by DefaultUploadOptions.
+const (
+	BlockSizeEnv   = "AZSTORECLI_BLOCK_SIZE"  // block size in MiB
+	ParallelismEnv = "AZSTORECLI_PARALLELISM" // concurrent block uploads
+)
+
+// maxBlocks is the service limit on blocks in a single block blob.
+const maxBlocks = 50000
+
+// UploadOptions controls how a file is split into blocks.
+type UploadOptions struct {
+	BlockSize   int64 // bytes per block; smaller files go up in a single Put Blob
+	Parallelism int   // blocks uploaded concurrently
+	ContentType string
+}
+
+// DefaultUploadOptions returns 4 MiB blocks and 4 workers, overridable via
+// $AZSTORECLI_BLOCK_SIZE and $AZSTORECLI_PARALLELISM.
+func DefaultUploadOptions() UploadOptions {
+	opts := UploadOptions{BlockSize: 4 << 20, Parallelism: 4}
+	if n, err := strconv.Atoi(os.Getenv(BlockSizeEnv)); err == nil && n > 0 {
+		opts.BlockSize = int64(n) << 20
+	}
+	if n, err := strconv.Atoi(os.Getenv(ParallelismEnv)); err == nil && n > 0 {
+		opts.Parallelism = n
+	}
+	return opts
+}
+
+// Progress is called with the bytes transferred so far and the total.
+type Progress func(done, total int64)
+
+// UploadFile uploads the local file at path to container/blob, using Put Blob
+// when it fits in one block and Put Block / Put Block List otherwise.
+func (c *Client) UploadFile(container, blob, path string, opts UploadOptions, progress Progress) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = mime.TypeByExtension(filepath.Ext(path))
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/octet-stream"
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = DefaultUploadOptions().BlockSize
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = 1
+	}
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+
+	size := info.Size()
+	if size <= opts.BlockSize {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		if err := c.PutBlob(container, blob, data, opts.ContentType); err != nil {
+			return err
+		}
+		progress(size, size)
+		return nil
+	}
+	return c.uploadBlocks(container, blob, f, size, opts, progress)
+}
+
+// PutBlob creates or replaces a block blob with data in a single request.
+func (c *Client) PutBlob(container, blob string, data []byte, contentType string) error {
+	req, err := c.newRequest(serviceBlob, http.MethodPut, blobPath(container, blob), nil, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.send(serviceBlob, req)
+}
+
+// uploadBlocks sends r in BlockSize chunks with Parallelism workers and commits them.
+func (c *Client) uploadBlocks(container, blob string, r io.ReaderAt, size int64, opts UploadOptions, progress Progress) error {
+	count := int((size + opts.BlockSize - 1) / opts.BlockSize)
+	if count > maxBlocks {
+		return fmt.Errorf("%d blocks exceed the limit of %d; use a larger block size", count, maxBlocks)
+	}
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%06d", i)))
+	}
+
+	var (
+		mu       sync.Mutex
+		done     int64
+		firstErr error
+		wg       sync.WaitGroup
+		next     = make(chan int)
+	)
+	for w := 0; w < opts.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, opts.BlockSize)
+			for i := range next {
+				off := int64(i) * opts.BlockSize
+				n, err := r.ReadAt(buf, off)
+				if err == io.EOF && off+int64(n) == size {
+					err = nil
+				}
+				if err == nil {
+					err = c.putBlock(container, blob, ids[i], buf[:n])
+				}
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				done += int64(n)
+				progress(done, size)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return c.putBlockList(container, blob, ids, opts.ContentType)
+}
+
+func (c *Client) putBlock(container, blob, id string, data []byte) error {
+	q := url.Values{"comp": {"block"}, "blockid": {id}}
+	req, err := c.newRequest(serviceBlob, http.MethodPut, blobPath(container, blob), q, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return c.send(serviceBlob, req)
+}
+
+func (c *Client) putBlockList(container, blob string, ids []string, contentType string) error {
+	list := struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}{Latest: ids}
+	body, err := xml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	q := url.Values{"comp": {"blocklist"}}
+	req, err := c.newRequest(serviceBlob, http.MethodPut, blobPath(container, blob), q, bytes.NewReader(append([]byte(xml.Header), body...)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	if contentType != "" {
+		req.Header.Set("x-ms-blob-content-type", contentType)
+	}
+	return c.send(serviceBlob, req)
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDefaultUploadOptions(t *testing.T) {
+	tests := []struct {
+		blockSize, parallelism string
+		want                   UploadOptions
+	}{
+		{"", "", UploadOptions{BlockSize: 4 << 20, Parallelism: 4}},
+		{"16", "8", UploadOptions{BlockSize: 16 << 20, Parallelism: 8}},
+		{"0", "-1", UploadOptions{BlockSize: 4 << 20, Parallelism: 4}},
+		{"big", "many", UploadOptions{BlockSize: 4 << 20, Parallelism: 4}},
+	}
+	for _, tt := range tests {
+		t.Setenv(BlockSizeEnv, tt.blockSize)
+		t.Setenv(ParallelismEnv, tt.parallelism)
+		if got := DefaultUploadOptions(); got != tt.want {
+			t.Errorf("%s=%q %s=%q: %+v, want %+v", BlockSizeEnv, tt.blockSize, ParallelismEnv, tt.parallelism, got, tt.want)
+		}
+	}
+}
+
+func writeTemp(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUploadFileSingleRequest(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	path := writeTemp(t, "report.json", `{"ok":true}`)
+	var done, total int64
+	if err := c.UploadFile("c", "dir/report.json", path, UploadOptions{BlockSize: 64}, func(d, tot int64) { done, total = d, tot }); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("%d requests, want 1", len(fake.requests))
+	}
+	r := fake.requests[0]
+	if got, want := r.Method+" "+r.URL, "PUT /devstoreaccount1/c/dir/report.json"; got != want {
+		t.Errorf("request %s, want %s", got, want)
+	}
+	if r.Header.Get("x-ms-blob-type") != "BlockBlob" || r.Header.Get("Content-Type") != "application/json" || string(r.Body) != `{"ok":true}` {
+		t.Errorf("Put Blob headers %v body %q", r.Header, r.Body)
+	}
+	if done != 11 || total != 11 {
+		t.Errorf("progress %d/%d", done, total)
+	}
+}
+
+func TestUploadFileBlocks(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	path := writeTemp(t, "data.bin", "abcdefghij")
+	if err := c.UploadFile("c", "b", path, UploadOptions{BlockSize: 4, Parallelism: 2}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 4 {
+		t.Fatalf("%d requests, want 3 blocks and a block list", len(fake.requests))
+	}
+
+	// Blocks go up concurrently, so sort them by ID
+	blocks := map[string]string{}
+	var ids []string
+	for _, r := range fake.requests[:3] {
+		u, _ := url.Parse(r.URL)
+		q := u.Query()
+		if r.Method != http.MethodPut || u.Path != "/devstoreaccount1/c/b" || q.Get("comp") != "block" {
+			t.Errorf("block request %s %s", r.Method, r.URL)
+		}
+		id := q.Get("blockid")
+		blocks[id] = string(r.Body)
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		raw, err := base64.StdEncoding.DecodeString(id)
+		if err != nil || string(raw) != fmt.Sprintf("block-%06d", i) {
+			t.Errorf("block ID %q decodes to %q, %v", id, raw, err)
+		}
+	}
+	if got := blocks[ids[0]] + "|" + blocks[ids[1]] + "|" + blocks[ids[2]]; got != "abcd|efgh|ij" {
+		t.Errorf("blocks = %s", got)
+	}
+
+	list := fake.requests[3]
+	if got, want := list.Method+" "+list.URL, "PUT /devstoreaccount1/c/b?comp=blocklist"; got != want {
+		t.Errorf("block list request %s, want %s", got, want)
+	}
+	if list.Header.Get("Content-Type") != "application/xml" || list.Header.Get("x-ms-blob-content-type") != "application/octet-stream" {
+		t.Errorf("block list headers %v", list.Header)
+	}
+	var committed struct {
+		Latest []string `xml:"Latest"`
+	}
+	if err := xml.Unmarshal(list.Body, &committed); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(committed.Latest, ids) {
+		t.Errorf("committed %v, want %v", committed.Latest, ids)
+	}
+}
+
+func TestUploadFileBlockFailure(t *testing.T) {
+	c, fake := newFakeClient(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		w.Header().Set("x-ms-error-code", "InternalError")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	path := writeTemp(t, "data.bin", "abcdefghij")
+	err := c.UploadFile("c", "b", path, UploadOptions{BlockSize: 4, Parallelism: 1}, nil)
+	if err == nil || !strings.Contains(err.Error(), "InternalError") {
+		t.Fatalf("UploadFile = %v", err)
+	}
+	for _, r := range fake.requests {
+		if strings.Contains(r.URL, "comp=blocklist") {
+			t.Error("block list committed after a failed block")
+		}
+	}
+}
+
+func TestUploadFileLimits(t *testing.T) {
+	c, fake := newFakeClient(t, nil)
+	path := writeTemp(t, "data.bin", strings.Repeat("x", maxBlocks+1))
+	err := c.UploadFile("c", "b", path, UploadOptions{BlockSize: 1}, nil)
+	if err == nil || !strings.Contains(err.Error(), "exceed the limit of 50000") {
+		t.Errorf("UploadFile = %v", err)
+	}
+	if err := c.UploadFile("c", "b", t.TempDir(), UploadOptions{}, nil); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("UploadFile of a directory = %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("%d requests sent", len(fake.requests))
+	}
+}
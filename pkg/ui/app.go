@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"sync"
 
 	"github.com/Linux-DEX/azstorecli/pkg/storage"
@@ -13,33 +14,50 @@ var (
 	activeRightIndex = 0
 
 	leftSections = []string{"Containers", "Queues", "File Shares", "Tables"}
-	leftData     = map[string][]string{
-		"Containers":  {"images", "videos", "backups"},
-		"Queues":      {"email-jobs", "task-queue"},
-		"File Shares": {"projectA", "projectB"},
-		"Tables":      {"users", "transactions"},
-	}
+	leftKinds    = []storage.ResourceKind{storage.Containers, storage.Queues, storage.Shares, storage.Tables}
 
-	rightData = map[string][]string{
-		"images": {"cat.png", "dog.jpg", "sunset.png"},
-		"videos": {"intro.mp4", "trailer.mov"},
-		"users":  {"alice", "bob", "charlie"},
-	}
+	// Filled from the backend by refreshLeft / loadRight
+	backend   storage.Backend
+	leftData  = map[string][]string{}
+	leftErr   = map[string]error{}
+	rightData []storage.Entry
+	rightErr  error
+	rightSeq  int // bumped on every fetchRight so stale replies are dropped
+
+	// Continuation token of the next unloaded page, and whether one is in flight
+	rightNext    string
+	rightLoading bool
+
+	// Result of the last upload/download or other action, shown in the right panel
+	statusMsg string
+
+	// Detail pane replacing the listing in the right panel ("", "preview", "properties", "entity" or "batch")
+	rightDetail string
+
+	// Virtual or share directory currently shown in the right panel ("" for the root)
+	rightPrefix string
 
 	focusSide = "left" // "left", "right", "logs"
 	showLogs  = false  // Controls whether logs or content are in the right panel
-	showPopup = true
 
-	logChan <-chan string
-	logsBuf []string
+	logChan  <-chan string
+	stopLogs context.CancelFunc // cancels the stream logChan comes from
+	logsBuf  []string
+
+	// Readiness of the services of the connected account, from waitAndRefresh
+	serviceHealth []storage.ServiceHealth
+
+	// Configured Azurite instances and the one the explorer is connected to
+	config  storage.Config
+	azurite storage.AzuriteConfig
 
 	// Global variables for safe goroutine cleanup
 	done chan struct{}
 	wg   sync.WaitGroup
 )
 
-// RunApp starts the GUI
-func RunApp() error {
+// RunApp starts the GUI, launching the active Azurite instance of conf
+func RunApp(conf storage.Config) error {
 	g, err := gocui.NewGui(gocui.OutputNormal, true)
 	if err != nil {
 		return err
@@ -50,6 +68,16 @@ func RunApp() error {
 	// Initialize channels & buffer
 	done = make(chan struct{})
 	logsBuf = []string{}
+	config = conf
+	if azurite, err = conf.Instance(conf.Active); err != nil {
+		return err
+	}
+	account, err := storage.DefaultAccount(azurite)
+	if err != nil {
+		return err
+	}
+	backend = storage.NewClient(account)
+	connection = describeAccount(account)
 
 	g.Cursor = false
 	g.Highlight = true
@@ -72,6 +100,26 @@ func RunApp() error {
 		{gocui.KeyEsc, gocui.ModNone, handleEsc},
 		{'L', gocui.ModNone, toggleLogs},
 		{'r', gocui.ModNone, reattachLogs},
+		{gocui.KeyF5, gocui.ModNone, refresh},
+		{'u', gocui.ModNone, uploadItem},
+		{'d', gocui.ModNone, downloadItem},
+		{'p', gocui.ModNone, propertiesItem},
+		{'m', gocui.ModNone, makeDirectory},
+		{'a', gocui.ModNone, addMessage},
+		{'g', gocui.ModNone, dequeueMessage},
+		{'e', gocui.ModNone, editItem},
+		{'x', gocui.ModNone, deleteItem},
+		{'X', gocui.ModNone, clearQueue},
+		{'f', gocui.ModNone, filterEntities},
+		{'i', gocui.ModNone, newEntity},
+		{'t', gocui.ModNone, cycleEditorType},
+		{'B', gocui.ModNone, showBatch},
+		{'n', gocui.ModNone, newResource},
+		{'D', gocui.ModNone, deleteResource},
+		{'?', gocui.ModNone, showHelp},
+		{'c', gocui.ModNone, copyAsJSON},
+		{'A', gocui.ModNone, showInstances},
+		{'M', gocui.ModNone, manageEmulator},
 		// Log scrolling keys still reference the "right" panel when showLogs is true
 		{gocui.KeyPgup, gocui.ModNone, scrollLogsUpPage},
 		{gocui.KeyPgdn, gocui.ModNone, scrollLogsDownPage},
@@ -83,16 +131,23 @@ func RunApp() error {
 		}
 	}
 
-	// Start Azurite logs
-	logChan, _ = storage.StartAzurite()
+	// Start Azurite and follow its logs
+	followLogs(g, func(ctx context.Context) (<-chan string, error) {
+		return storage.StartAzurite(ctx, azurite)
+	})
 
-	// Start logs listener
+	// Keep the status bar's emulator state current
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		listenLogs(g)
+		watchEmulator(g)
 	}()
 
+	openMessage(g, "Welcome!", welcomeText)
+
+	// Fill the left panels once the emulator answers
+	go waitAndRefresh(g, backend)
+
 	// Run main GUI loop
 	err = g.MainLoop()
 
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Table batch ---
+// Entity writes and deletes can be staged into batchOps instead of being sent
+// one by one, then submitted together as one entity group transaction. A batch
+// covers a single table and PartitionKey. While rightDetail is "batch" the
+// right panel lists the staged operations and, once submitted, their results.
+var (
+	batchTable     string
+	batchOps       []storage.BatchOperation
+	batchResults   []storage.BatchResult
+	batchSubmitted bool // batchResults are from a batch that was applied
+	batchIndex     int
+)
+
+// stageBatchOp adds op to the batch, starting a new one if the previous batch
+// was submitted. It refuses operations for another table or partition.
+func stageBatchOp(g *gocui.Gui, table string, op storage.BatchOperation) bool {
+	if batchSubmitted {
+		batchOps, batchResults, batchSubmitted = nil, nil, false
+	}
+	if len(batchOps) > 0 && (table != batchTable || op.Entity.PartitionKey != batchOps[0].Entity.PartitionKey) {
+		setStatus(g, "The batch is for %s partition %q; submit or clear it first", batchTable, batchOps[0].Entity.PartitionKey)
+		return false
+	}
+	batchTable = table
+	batchOps = append(batchOps, op)
+	batchResults = nil
+	setStatus(g, "Staged %s (%d in batch, B to review)", op, len(batchOps))
+	return true
+}
+
+// stageEditor adds the entity editor's contents to the batch and closes it.
+func stageEditor(g *gocui.Gui) {
+	op := storage.BatchOperation{Mode: editMode, Entity: editEntity, ETag: editETag}
+	if stageBatchOp(g, editTable, op) {
+		closeDetail()
+	}
+}
+
+// showBatch opens the staged batch in the right panel.
+func showBatch(g *gocui.Gui, v *gocui.View) error {
+	if showLogs || rightDetail != "" || leftKinds[activeSection] != storage.Tables {
+		return nil
+	}
+	rightDetail = "batch"
+	batchIndex = 0
+	focusSide = "right"
+	g.Update(func(gui *gocui.Gui) error { return nil })
+	return nil
+}
+
+// batchRowCount is the staged operations plus the Submit and Clear rows.
+func batchRowCount() int {
+	return len(batchOps) + 2
+}
+
+// layoutBatch renders the staged operations into the right panel.
+func layoutBatch(v *gocui.View) {
+	v.Title = fmt.Sprintf("Batch for %s (Enter submit, X unstage, Esc close)", batchTable)
+	if len(batchOps) == 0 {
+		v.Title = "Batch (empty; stage from the entity editor or with x on an entity)"
+	}
+	batchIndex = min(batchIndex, batchRowCount()-1)
+	for i := 0; i < batchRowCount(); i++ {
+		prefix := "  "
+		if i == batchIndex {
+			prefix = "> "
+		}
+		var line string
+		switch {
+		case i < len(batchOps):
+			line = fmt.Sprintf("%3d. %s", i+1, batchOps[i])
+			if i < len(batchResults) {
+				line = fmt.Sprintf("%-48s %s", line, batchResultText(batchResults[i]))
+			}
+		case i == len(batchOps):
+			line = "[ Submit batch ]"
+		default:
+			line = "[ Clear batch ]"
+		}
+		fmt.Fprintln(v, prefix+line)
+	}
+	scrollTo(v, batchIndex)
+}
+
+func batchResultText(r storage.BatchResult) string {
+	switch {
+	case r.Err != nil && r.StatusCode == 0:
+		return "- " + r.Err.Error()
+	case r.Err != nil:
+		return "✗ " + r.Err.Error()
+	}
+	return fmt.Sprintf("✓ %d", r.StatusCode)
+}
+
+// closeBatch leaves the batch view, reloading the listing if the batch changed it.
+func closeBatch(g *gocui.Gui) {
+	closeDetail()
+	if batchSubmitted {
+		refetchIf(batchTable)(g)
+	}
+}
+
+func moveBatch(delta int) {
+	batchIndex = min(max(batchIndex+delta, 0), batchRowCount()-1)
+}
+
+// activateBatchRow handles Enter on the selected row of the batch view.
+func activateBatchRow(g *gocui.Gui) {
+	switch {
+	case batchIndex == len(batchOps):
+		submitBatch(g)
+	case batchIndex == len(batchOps)+1:
+		batchOps, batchResults, batchSubmitted = nil, nil, false
+		batchIndex = 0
+	}
+}
+
+// unstageBatchOp drops the selected operation from the batch.
+func unstageBatchOp() {
+	if batchIndex < len(batchOps) && !batchSubmitted {
+		batchOps = append(batchOps[:batchIndex], batchOps[batchIndex+1:]...)
+		batchResults = nil
+	}
+}
+
+func submitBatch(g *gocui.Gui) {
+	if len(batchOps) == 0 || batchSubmitted {
+		return
+	}
+	table, ops := batchTable, append([]storage.BatchOperation(nil), batchOps...)
+	setStatus(g, "Submitting %d operations…", len(ops))
+	b := backend
+	go func() {
+		results, err := b.SubmitBatch(table, ops)
+		if err != nil {
+			setStatus(g, "Error: %v", err)
+			return
+		}
+		applied := true
+		for _, r := range results {
+			applied = applied && r.Err == nil
+		}
+		if applied {
+			setStatus(g, "Batch of %d operations applied to %s", len(ops), table)
+		} else {
+			setStatus(g, "Batch rejected, nothing was applied; unstage or fix the failed operation")
+		}
+		g.Update(func(gui *gocui.Gui) error {
+			if batchTable == table && len(batchOps) == len(ops) {
+				batchResults, batchSubmitted = results, applied
+			}
+			refetchIf(table)(gui)
+			return nil
+		})
+	}()
+}
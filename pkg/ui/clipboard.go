@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/output"
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Copy as JSON ---
+
+// clipboardTools are tried in order; each reads the text on stdin.
+var clipboardTools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+var errNoClipboard = errors.New("no clipboard tool found")
+
+// copyToClipboard hands text to the first clipboard tool found on PATH.
+func copyToClipboard(text string) error {
+	for _, tool := range clipboardTools {
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errNoClipboard
+}
+
+// selectedJSON renders the entity, message, blob or file under the right
+// cursor as an indented JSON object.
+func selectedJSON() (string, string, bool) {
+	if showLogs || focusSide != "right" || rightDetail != "" || activeRightIndex >= len(rightData) {
+		return "", "", false
+	}
+	e := rightData[activeRightIndex]
+	var t output.Table
+	switch {
+	case e.Entity != nil:
+		t = output.Entities([]storage.Entity{*e.Entity}, "")
+	case e.Message != nil:
+		t = output.Messages([]storage.QueueMessage{*e.Message})
+	default:
+		t = output.Entries([]storage.Entry{e})
+	}
+	data, err := output.MarshalRow(t.Columns, t.Rows[0])
+	if err != nil {
+		return "", "", false
+	}
+	var out bytes.Buffer
+	json.Indent(&out, data, "", "  ")
+	return e.Name, out.String(), true
+}
+
+// copyAsJSON copies the selected item as JSON. Without a clipboard tool the
+// JSON is shown in the preview pane instead, to be copied from the terminal.
+func copyAsJSON(g *gocui.Gui, v *gocui.View) error {
+	name, text, ok := selectedJSON()
+	if !ok {
+		return nil
+	}
+	go func() {
+		err := copyToClipboard(text)
+		switch {
+		case errors.Is(err, errNoClipboard):
+			g.Update(func(gui *gocui.Gui) error {
+				rightDetail = "preview"
+				previewTitle = fmt.Sprintf("JSON of %s (no clipboard tool found, Esc to close)", name)
+				previewBody, previewOrigin = text, 0
+				return nil
+			})
+		case err != nil:
+			setStatus(g, "Copy failed: %v", err)
+		default:
+			setStatus(g, "Copied %s as JSON", name)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Data loading ---
+
+// waitAndRefresh probes the blob, queue and table services of b until they
+// answer, showing their health in the status line, then fills the left
+// panels, unless another instance was connected meanwhile.
+func waitAndRefresh(g *gocui.Gui, b storage.Backend) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := b.WaitReady(ctx, storage.ReadyTimeout, func(health []storage.ServiceHealth) {
+		g.Update(func(gui *gocui.Gui) error {
+			if b == backend {
+				serviceHealth = health
+				statusMsg = "Waiting for storage: " + healthSummary(health)
+			}
+			return nil
+		})
+	})
+	select {
+	case <-done:
+		return
+	default:
+	}
+	g.Update(func(gui *gocui.Gui) error {
+		if b != backend {
+			return nil
+		}
+		if err != nil {
+			statusMsg = "Storage " + err.Error()
+		} else {
+			statusMsg = "Storage ready: " + healthSummary(serviceHealth)
+		}
+		refreshLeft(gui)
+		return nil
+	})
+}
+
+// healthSummary renders service health as e.g. "blob ✓ queue ✓ table ✗".
+func healthSummary(health []storage.ServiceHealth) string {
+	parts := make([]string, len(health))
+	for i, h := range health {
+		mark := "✗"
+		if h.Ready {
+			mark = "✓"
+		}
+		parts[i] = h.Service + " " + mark
+	}
+	return strings.Join(parts, " ")
+}
+
+// refreshLeft reloads every left section from the backend in the background.
+func refreshLeft(g *gocui.Gui) {
+	refreshLeftTo(g, "")
+}
+
+// refreshLeftTo is refreshLeft, then moves the cursor to the item called
+// name in the active section if it is there. Replies are dropped if another
+// instance was connected meanwhile.
+func refreshLeftTo(g *gocui.Gui, name string) {
+	b := backend
+	go func() {
+		data := map[string][]string{}
+		errs := map[string]error{}
+		for i, name := range leftSections {
+			data[name], errs[name] = listSection(b, leftKinds[i])
+		}
+		g.Update(func(gui *gocui.Gui) error {
+			if b != backend {
+				return nil
+			}
+			leftData, leftErr = data, errs
+			if n := len(leftData[leftSections[activeSection]]); activeLeftIndex >= n {
+				activeLeftIndex = 0
+			}
+			for i, item := range leftData[leftSections[activeSection]] {
+				if name != "" && item == name {
+					activeLeftIndex = i
+				}
+			}
+			loadRight(gui)
+			return nil
+		})
+	}()
+}
+
+func listSection(b storage.Backend, kind storage.ResourceKind) ([]string, error) {
+	switch kind {
+	case storage.Containers:
+		return b.ListContainers()
+	case storage.Queues:
+		return b.ListQueues()
+	case storage.Shares:
+		return b.ListShares()
+	case storage.Tables:
+		return b.ListTables()
+	}
+	return nil, fmt.Errorf("unknown section %d", kind)
+}
+
+// selectedLeft returns the name of the highlighted left item, or "" if the section is empty.
+func selectedLeft() string {
+	items := leftData[leftSections[activeSection]]
+	if activeLeftIndex >= len(items) {
+		return ""
+	}
+	return items[activeLeftIndex]
+}
+
+// loadRight shows the top level of the highlighted left item.
+func loadRight(g *gocui.Gui) {
+	rightPrefix = ""
+	fetchRight(g)
+}
+
+// fetchRight lists the first page of the highlighted left item at rightPrefix.
+func fetchRight(g *gocui.Gui) {
+	rightSeq++
+	gridOffsetX = 0
+	closeDetail()
+	rightData, rightErr, rightNext = nil, nil, ""
+	activeRightIndex = 0
+	fetchPage(g, "")
+}
+
+// fetchMore appends the next page once the cursor reaches the end of what is loaded.
+func fetchMore(g *gocui.Gui) {
+	if rightNext == "" || rightLoading {
+		return
+	}
+	fetchPage(g, rightNext)
+}
+
+// fetchPage loads the page at token in the background and appends it to rightData.
+func fetchPage(g *gocui.Gui, token string) {
+	name := selectedLeft()
+	if name == "" {
+		rightLoading = false
+		return
+	}
+	seq := rightSeq
+	kind := leftKinds[activeSection]
+	prefix := rightPrefix
+	query := activeTableQuery(name)
+	b := backend
+	rightLoading = true
+	go func() {
+		var page storage.Page
+		var err error
+		if kind == storage.Tables && query != (storage.TableQuery{}) {
+			page, err = b.QueryEntities(name, query, token)
+		} else {
+			page, err = b.ListChildren(kind, name, prefix, token)
+		}
+		g.Update(func(gui *gocui.Gui) error {
+			if seq != rightSeq {
+				return nil
+			}
+			rightLoading = false
+			rightErr = err
+			if err == nil {
+				rightData = append(rightData, page.Entries...)
+				rightNext = page.Next
+			}
+			return nil
+		})
+	}()
+}
+
+// runAction runs fn in the background, reports the outcome with setStatus and
+// then calls after (if any) on the GUI goroutine.
+func runAction(g *gocui.Gui, okMsg string, fn func() error, after func(*gocui.Gui)) {
+	go func() {
+		if err := fn(); err != nil {
+			setStatus(g, "Error: %v", err)
+		} else {
+			setStatus(g, "%s", okMsg)
+		}
+		if after != nil {
+			g.Update(func(gui *gocui.Gui) error {
+				after(gui)
+				return nil
+			})
+		}
+	}()
+}
+
+// refetchIf returns an after-hook for runAction that reloads the right panel
+// if it still shows the given left item.
+func refetchIf(name string) func(*gocui.Gui) {
+	return func(g *gocui.Gui) {
+		if selectedLeft() == name && rightDetail == "" {
+			fetchRight(g)
+		}
+	}
+}
+
+// closeDetail returns the right panel from a preview or properties view to the listing.
+func closeDetail() {
+	rightDetail = ""
+	previewBody = ""
+}
+
+func refresh(g *gocui.Gui, v *gocui.View) error {
+	refreshLeft(g)
+	return nil
+}
@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Entity editor ---
+// While rightDetail is "entity" the right panel is a form for editEntity.
+// editETag is the ETag the entity had when it was opened, sent as If-Match.
+// The keys of an existing entity are read-only: the ETag belongs to them.
+var (
+	editTable    string
+	editEntity   storage.Entity
+	editETag     string
+	editMode     storage.WriteMode
+	editIndex    int
+	editExisting bool
+)
+
+// editorRow is one line of the entity form.
+type editorRow struct {
+	kind string // "mode", "pk", "rk", "prop", "add", "save" or "stage"
+	name string // property name for "prop" rows
+}
+
+// selectedTable returns the highlighted table, if the Tables section is active.
+func selectedTable() (string, bool) {
+	if showLogs || leftKinds[activeSection] != storage.Tables {
+		return "", false
+	}
+	name := selectedLeft()
+	return name, name != ""
+}
+
+// selectedEntity returns the entity under the right cursor.
+func selectedEntity() (string, *storage.Entity, bool) {
+	table, ok := selectedTable()
+	if !ok || focusSide != "right" || rightDetail != "" || activeRightIndex >= len(rightData) {
+		return "", nil, false
+	}
+	e := rightData[activeRightIndex].Entity
+	return table, e, e != nil
+}
+
+func newEntity(g *gocui.Gui, v *gocui.View) error {
+	table, ok := selectedTable()
+	if !ok || rightDetail != "" {
+		return nil
+	}
+	openEditor(table, storage.Entity{Properties: map[string]storage.Property{}}, storage.Insert, false)
+	return nil
+}
+
+func editEntityItem(g *gocui.Gui) {
+	table, e, ok := selectedEntity()
+	if !ok {
+		return
+	}
+	copied := *e
+	copied.Properties = map[string]storage.Property{}
+	for k, p := range e.Properties {
+		copied.Properties[k] = p
+	}
+	openEditor(table, copied, storage.Replace, true)
+}
+
+func openEditor(table string, e storage.Entity, mode storage.WriteMode, existing bool) {
+	rightDetail = "entity"
+	editTable, editEntity, editETag, editMode, editExisting = table, e, e.ETag, mode, existing
+	editIndex = 0
+}
+
+func editorRows() []editorRow {
+	rows := []editorRow{{kind: "mode"}, {kind: "pk"}, {kind: "rk"}}
+	names := make([]string, 0, len(editEntity.Properties))
+	for name := range editEntity.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rows = append(rows, editorRow{kind: "prop", name: name})
+	}
+	return append(rows, editorRow{kind: "add"}, editorRow{kind: "save"}, editorRow{kind: "stage"})
+}
+
+// layoutEditor renders the entity form into the right panel.
+func layoutEditor(v *gocui.View) {
+	v.Title = fmt.Sprintf("Entity in %s (Enter edit, T type, X remove, Esc discard)", editTable)
+	rows := editorRows()
+	if editIndex >= len(rows) {
+		editIndex = len(rows) - 1
+	}
+	for i, r := range rows {
+		prefix := "  "
+		if i == editIndex {
+			prefix = "> "
+		}
+		var line string
+		switch r.kind {
+		case "mode":
+			line = fmt.Sprintf("%-24s %s", "Mode:", editMode)
+			if editETag != "" && (editMode == storage.Replace || editMode == storage.Merge) {
+				line += "  (If-Match " + editETag + ")"
+			}
+		case "pk":
+			line = fmt.Sprintf("%-24s %s", "PartitionKey:", editEntity.PartitionKey)
+			if editExisting {
+				line += "  (read-only)"
+			}
+		case "rk":
+			line = fmt.Sprintf("%-24s %s", "RowKey:", editEntity.RowKey)
+			if editExisting {
+				line += "  (read-only)"
+			}
+		case "prop":
+			p := editEntity.Properties[r.name]
+			line = fmt.Sprintf("%-24s %s", fmt.Sprintf("%s (%s):", r.name, strings.TrimPrefix(string(p.Type), "Edm.")), p.Value)
+		case "add":
+			line = "+ Add property"
+		case "save":
+			line = "[ Save ]"
+		case "stage":
+			line = "[ Add to batch ]"
+		}
+		fmt.Fprintln(v, prefix+line)
+	}
+	scrollTo(v, editIndex)
+}
+
+func moveEditor(delta int) {
+	editIndex = min(max(editIndex+delta, 0), len(editorRows())-1)
+}
+
+// activateEditorRow handles Enter on the selected row of the form.
+func activateEditorRow(g *gocui.Gui) {
+	rows := editorRows()
+	if editIndex >= len(rows) {
+		return
+	}
+	r := rows[editIndex]
+	if editExisting && (r.kind == "pk" || r.kind == "rk") {
+		setStatus(g, "The keys of an existing entity cannot change; insert a new entity and delete this one instead")
+		return
+	}
+	switch r.kind {
+	case "mode":
+		names := make([]string, len(storage.WriteModes))
+		for i, m := range storage.WriteModes {
+			names[i] = m.String()
+		}
+		openPicker(g, "Write mode", names, int(editMode), func(g *gocui.Gui, choice int) error {
+			editMode = storage.WriteModes[choice]
+			return nil
+		})
+	case "pk":
+		openPrompt(g, "PartitionKey", editEntity.PartitionKey, func(g *gocui.Gui, value string) error {
+			editEntity.PartitionKey = value
+			return nil
+		})
+	case "rk":
+		openPrompt(g, "RowKey", editEntity.RowKey, func(g *gocui.Gui, value string) error {
+			editEntity.RowKey = value
+			return nil
+		})
+	case "prop":
+		p := editEntity.Properties[r.name]
+		openPrompt(g, fmt.Sprintf("Value of %s (%s)", r.name, p.Type), p.Value, func(g *gocui.Gui, value string) error {
+			p.Value = value
+			editEntity.Properties[r.name] = p
+			return nil
+		})
+	case "add":
+		openPrompt(g, "New property as name[:Type]=value, Type one of "+edmTypeNames(), "", addEditorProperty)
+	case "save":
+		saveEditor(g)
+	case "stage":
+		stageEditor(g)
+	}
+}
+
+func edmTypeNames() string {
+	names := make([]string, len(storage.EdmTypes))
+	for i, t := range storage.EdmTypes {
+		names[i] = strings.TrimPrefix(string(t), "Edm.")
+	}
+	return strings.Join(names, "/")
+}
+
+func addEditorProperty(g *gocui.Gui, value string) error {
+	if value == "" {
+		return nil
+	}
+	key, val, _ := strings.Cut(value, "=")
+	name, typ, hasType := strings.Cut(strings.TrimSpace(key), ":")
+	p := storage.Property{Type: storage.EdmString, Value: val}
+	if hasType {
+		p.Type = ""
+		for _, t := range storage.EdmTypes {
+			if strings.EqualFold(typ, strings.TrimPrefix(string(t), "Edm.")) || strings.EqualFold(typ, string(t)) {
+				p.Type = t
+			}
+		}
+		if p.Type == "" {
+			setStatus(g, "Unknown type %q, want one of %s", typ, edmTypeNames())
+			return nil
+		}
+	}
+	if name == "" || name == "PartitionKey" || name == "RowKey" || name == "Timestamp" {
+		setStatus(g, "Invalid property name %q", name)
+		return nil
+	}
+	editEntity.Properties[name] = p
+	return nil
+}
+
+// cycleEditorType switches the selected property to the next Edm type.
+func cycleEditorType(g *gocui.Gui, v *gocui.View) error {
+	rows := editorRows()
+	if rightDetail != "entity" || editIndex >= len(rows) || rows[editIndex].kind != "prop" {
+		return nil
+	}
+	name := rows[editIndex].name
+	p := editEntity.Properties[name]
+	for i, t := range storage.EdmTypes {
+		if t == p.Type {
+			p.Type = storage.EdmTypes[(i+1)%len(storage.EdmTypes)]
+			break
+		}
+	}
+	editEntity.Properties[name] = p
+	g.Update(func(gui *gocui.Gui) error { return nil })
+	return nil
+}
+
+// removeEditorProperty drops the selected property from the form.
+func removeEditorProperty() {
+	rows := editorRows()
+	if editIndex < len(rows) && rows[editIndex].kind == "prop" {
+		delete(editEntity.Properties, rows[editIndex].name)
+	}
+}
+
+func saveEditor(g *gocui.Gui) {
+	table, e, mode, etag := editTable, editEntity, editMode, editETag
+	b := backend
+	go func() {
+		err := b.SaveEntity(table, e, mode, etag)
+		var rerr *storage.ResponseError
+		if errors.As(err, &rerr) && rerr.StatusCode == http.StatusPreconditionFailed {
+			setStatus(g, "Entity was changed since it was opened (ETag mismatch); reload and edit again")
+			return
+		}
+		if err != nil {
+			setStatus(g, "Error: %v", err)
+			return
+		}
+		setStatus(g, "Saved %s/%s (%s)", e.PartitionKey, e.RowKey, mode)
+		g.Update(func(gui *gocui.Gui) error {
+			if rightDetail == "entity" && editTable == table {
+				closeDetail()
+				refetchIf(table)(gui)
+			}
+			return nil
+		})
+	}()
+}
+
+func deleteEntityItem(g *gocui.Gui) {
+	table, e, ok := selectedEntity()
+	if !ok {
+		return
+	}
+	pk, rk, etag := e.PartitionKey, e.RowKey, e.ETag
+	options := []string{"Delete now", "Add the delete to the batch"}
+	openPicker(g, fmt.Sprintf("Delete entity %s/%s?", pk, rk), options, 0, func(g *gocui.Gui, choice int) error {
+		if choice == 1 {
+			stageBatchOp(g, table, storage.BatchOperation{Delete: true, Entity: *e, ETag: etag})
+			return nil
+		}
+		b := backend
+		runAction(g, fmt.Sprintf("Deleted %s/%s", pk, rk), func() error {
+			return b.DeleteEntity(table, pk, rk, etag)
+		}, refetchIf(table))
+		return nil
+	})
+}
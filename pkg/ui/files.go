@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- File shares ---
+// Shares are browsed like containers: rightPrefix is the directory shown,
+// ending in "/", and Enter on a directory descends into it.
+
+// selectedShare returns the highlighted share, if the File Shares section is active.
+func selectedShare() (string, bool) {
+	if showLogs || leftKinds[activeSection] != storage.Shares {
+		return "", false
+	}
+	name := selectedLeft()
+	return name, name != ""
+}
+
+// selectedFile returns the share and the file or directory under the right cursor.
+func selectedFile() (share string, e storage.Entry, ok bool) {
+	share, ok = selectedShare()
+	if !ok || focusSide != "right" || rightDetail != "" || activeRightIndex >= len(rightData) {
+		return "", storage.Entry{}, false
+	}
+	return share, rightData[activeRightIndex], true
+}
+
+func uploadShareFile(g *gocui.Gui) {
+	share, ok := selectedShare()
+	if !ok {
+		return
+	}
+	dir := rightPrefix
+	openPrompt(g, fmt.Sprintf("Upload local file to %s/%s", share, dir), "", func(g *gocui.Gui, path string) error {
+		if path == "" {
+			return nil
+		}
+		path = expandHome(path)
+		name := dir + filepath.Base(path)
+		b := backend
+		go func() {
+			err := b.UploadShareFile(share, name, path, func(done, total int64) {
+				if total > 0 {
+					setStatus(g, "Uploading %s: %d%%", name, done*100/total)
+				}
+			})
+			if err != nil {
+				setStatus(g, "Upload of %s failed: %v", name, err)
+				return
+			}
+			setStatus(g, "Uploaded %s", name)
+			g.Update(func(gui *gocui.Gui) error {
+				if selectedLeft() == share && rightPrefix == dir && rightDetail == "" {
+					fetchRight(gui)
+				}
+				return nil
+			})
+		}()
+		return nil
+	})
+}
+
+func downloadShareFile(g *gocui.Gui) {
+	share, e, ok := selectedFile()
+	if !ok {
+		return
+	}
+	if e.IsDir {
+		downloadShareDirectory(g, share, e)
+		return
+	}
+	openPrompt(g, fmt.Sprintf("Download %s/%s to", share, e.Path), "./"+e.Name, func(g *gocui.Gui, path string) error {
+		if path == "" {
+			return nil
+		}
+		path = expandHome(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, e.Name)
+		}
+		b := backend
+		go func() {
+			err := b.DownloadShareFile(share, e.Path, path, func(done, total int64) {
+				if total > 0 {
+					setStatus(g, "Downloading %s: %d%%", e.Path, done*100/total)
+				}
+			})
+			if err != nil {
+				setStatus(g, "Download of %s failed: %v", e.Path, err)
+				return
+			}
+			setStatus(g, "Downloaded %s to %s", e.Path, path)
+		}()
+		return nil
+	})
+}
+
+// downloadShareDirectory copies a directory and everything below it into a
+// local directory of the same name.
+func downloadShareDirectory(g *gocui.Gui, share string, e storage.Entry) {
+	name := strings.TrimSuffix(e.Name, "/")
+	openPrompt(g, fmt.Sprintf("Download %s/%s into", share, e.Path), "./"+name, func(g *gocui.Gui, path string) error {
+		if path == "" {
+			return nil
+		}
+		path = expandHome(path)
+		b := backend
+		go func() {
+			n, err := downloadTree(b, share, e.Path, path, func(file string) {
+				setStatus(g, "Downloading %s", file)
+			})
+			if err != nil {
+				setStatus(g, "Download of %s failed after %d files: %v", e.Path, n, err)
+				return
+			}
+			setStatus(g, "Downloaded %d files of %s to %s", n, e.Path, path)
+		}()
+		return nil
+	})
+}
+
+// downloadTree copies the files under dir of share into local, creating the
+// directories on the way, and returns how many files it copied.
+func downloadTree(b storage.Backend, share, dir, local string, progress func(file string)) (int, error) {
+	if err := os.MkdirAll(local, 0o755); err != nil {
+		return 0, err
+	}
+	n, token := 0, ""
+	for {
+		page, err := b.ListChildren(storage.Shares, share, dir, token)
+		if err != nil {
+			return n, err
+		}
+		for _, e := range page.Entries {
+			target := filepath.Join(local, strings.TrimSuffix(e.Name, "/"))
+			if e.IsDir {
+				copied, err := downloadTree(b, share, e.Path, target, progress)
+				n += copied
+				if err != nil {
+					return n, err
+				}
+				continue
+			}
+			progress(e.Path)
+			if err := b.DownloadShareFile(share, e.Path, target, nil); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if page.Next == "" {
+			return n, nil
+		}
+		token = page.Next
+	}
+}
+
+// makeDirectory creates a directory inside the one currently shown.
+func makeDirectory(g *gocui.Gui, v *gocui.View) error {
+	share, ok := selectedShare()
+	if !ok || rightDetail != "" {
+		return nil
+	}
+	dir := rightPrefix
+	openPrompt(g, fmt.Sprintf("New directory in %s/%s", share, dir), "", func(g *gocui.Gui, name string) error {
+		name = strings.Trim(name, "/")
+		if name == "" {
+			return nil
+		}
+		b := backend
+		runAction(g, "Created "+dir+name+"/", func() error {
+			return b.CreateDirectory(share, dir+name)
+		}, refetchIf(share))
+		return nil
+	})
+	return nil
+}
+
+func deleteShareItem(g *gocui.Gui) {
+	share, e, ok := selectedFile()
+	if !ok {
+		return
+	}
+	what := "file"
+	if e.IsDir {
+		what = "empty directory"
+	}
+	openConfirm(g, "Delete "+what, fmt.Sprintf("Delete %s/%s?", share, e.Path), func(g *gocui.Gui) error {
+		b := backend
+		runAction(g, "Deleted "+e.Path, func() error {
+			if e.IsDir {
+				return b.DeleteDirectory(share, e.Path)
+			}
+			return b.DeleteFile(share, e.Path)
+		}, refetchIf(share))
+		return nil
+	})
+}
+
+// showFileProperties shows the properties and SMB attributes of the selected
+// file or directory in the preview pane.
+func showFileProperties(g *gocui.Gui) {
+	share, e, ok := selectedFile()
+	if !ok {
+		return
+	}
+	rightDetail = "preview"
+	previewTitle = fmt.Sprintf("Properties of %s/%s (loading…)", share, e.Path)
+	previewBody = ""
+	previewOrigin = 0
+	seq := rightSeq
+	b := backend
+	go func() {
+		props, err := b.GetFileProperties(share, e.Path)
+		g.Update(func(gui *gocui.Gui) error {
+			if seq != rightSeq || rightDetail != "preview" {
+				return nil
+			}
+			previewTitle = fmt.Sprintf("Properties of %s/%s (Esc to close)", share, e.Path)
+			if err != nil {
+				previewBody = fmt.Sprintf("Error: %v", err)
+			} else {
+				previewBody = renderFileProperties(props)
+			}
+			return nil
+		})
+	}()
+}
+
+func renderFileProperties(p storage.FileProperties) string {
+	stamp := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format(time.RFC1123)
+	}
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%-20s %s\n", label+":", value)
+	}
+	if !p.IsDir {
+		row("Content-Length", fmt.Sprintf("%d (%s)", p.ContentLength, formatSize(p.ContentLength)))
+		row("Content-Type", p.ContentType)
+		row("Content-MD5", p.ContentMD5)
+	}
+	row("ETag", p.ETag)
+	row("Last-Modified", stamp(p.LastModified))
+	row("SMB Attributes", p.Attributes)
+	row("Creation Time", stamp(p.CreationTime))
+	row("Last Write Time", stamp(p.LastWriteTime))
+	row("Change Time", stamp(p.ChangeTime))
+	row("Permission Key", p.PermissionKey)
+	row("File ID", p.FileID)
+	row("Parent ID", p.ParentID)
+	keys := make([]string, 0, len(p.Metadata))
+	for k := range p.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		row("meta: "+k, p.Metadata[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Azurite instances ---
+// The config file can name several Azurite instances. A lists them with the
+// state of their containers; each can be managed (see lifecycle.go), and
+// connecting to one points the explorer and the log panel at it.
+
+// showInstances looks up the state of every instance and opens a picker.
+func showInstances(g *gocui.Gui, v *gocui.View) error {
+	if len(modals) > 0 {
+		return nil
+	}
+	instances := append([]storage.AzuriteConfig(nil), config.Instances...)
+	connected := azurite.Name
+	setStatus(g, "Checking Azurite instances…")
+	go func() {
+		options := make([]string, len(instances))
+		states := make([]string, len(instances))
+		selected := 0
+		for i, inst := range instances {
+			_, state, err := storage.AzuriteStatus(inst)
+			switch {
+			case err != nil:
+				state = "unknown"
+			case state == "":
+				state = "not created"
+			}
+			states[i] = state
+			marker := "  "
+			if inst.Name == connected {
+				marker, selected = "* ", i
+			}
+			options[i] = fmt.Sprintf("%s%-16s %-12s ports %d/%d/%d", marker, inst.Name, state,
+				inst.BlobPort, inst.QueuePort, inst.TablePort)
+		}
+		setStatus(g, "")
+		g.Update(func(gui *gocui.Gui) error {
+			openPicker(gui, "Azurite instances (* = connected)", options, selected, func(g *gocui.Gui, i int) error {
+				instanceActions(g, instances[i], states[i])
+				return nil
+			})
+			return nil
+		})
+	}()
+	return nil
+}
+
+// connectInstance starts inst if needed, switches the backend and the log
+// panel to it and reloads everything once it answers.
+func connectInstance(g *gocui.Gui, inst storage.AzuriteConfig) {
+	azurite = inst
+	config.Active = inst.Name
+	backend = storage.NewClient(inst.Account())
+	connection = describeAccount(inst.Account())
+	emulatorID, emulatorState, emulatorVersion = "", "", ""
+
+	leftData, leftErr = map[string][]string{}, map[string]error{}
+	activeLeftIndex, activeRightIndex = 0, 0
+	rightData, rightErr, rightNext = nil, nil, ""
+	rightPrefix = ""
+	rightSeq++
+	closeDetail()
+	focusSide = "left"
+	if showLogs {
+		focusSide = "logs"
+	}
+
+	followLogs(g, func(ctx context.Context) (<-chan string, error) {
+		return storage.StartAzurite(ctx, inst)
+	})
+	statusMsg = "Connecting to " + inst.Name + "…"
+	serviceHealth = nil
+	go waitAndRefresh(g, backend)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
 	"github.com/awesome-gocui/gocui"
 )
 
@@ -11,8 +12,8 @@ import (
 func layout(g *gocui.Gui) error {
 	maxX, maxY := g.Size()
 	leftWidth := maxX / 3
-	contentTop := 1
-	contentBottom := maxY - 1
+	contentTop := 1 // row 0 and the last row hold the status bar
+	contentBottom := maxY - 2
 	totalHeight := contentBottom - contentTop + 1
 
 	// --- Left panel: distribute heights evenly and handle remainder ---
@@ -44,6 +45,9 @@ func layout(g *gocui.Gui) error {
 		v.SelFgColor = gocui.ColorCyan
 
 		items := leftData[name]
+		if err := leftErr[name]; err != nil {
+			fmt.Fprintf(v, "  %v\n", err)
+		}
 		for j, item := range items {
 			prefix := "  "
 			if focusSide == "left" && i == activeSection && j == activeLeftIndex {
@@ -53,7 +57,7 @@ func layout(g *gocui.Gui) error {
 		}
 
 		if i == activeSection && focusSide == "left" {
-			v.SetCursor(0, activeLeftIndex)
+			scrollTo(v, activeLeftIndex)
 		} else {
 			v.SetCursor(0, 0)
 		}
@@ -76,34 +80,70 @@ func layout(g *gocui.Gui) error {
 		for _, line := range logsBuf {
 			fmt.Fprintln(right, line)
 		}
+	} else if rightDetail == "preview" {
+		right.Title = previewTitle + " (Esc to close)"
+		right.Highlight = false
+		fmt.Fprint(right, previewBody)
+		if n := right.ViewLinesHeight(); previewOrigin >= n {
+			previewOrigin = max(n-1, 0)
+		}
+		right.SetOrigin(0, previewOrigin)
+	} else if rightDetail == "properties" {
+		right.Highlight = true
+		right.SelFgColor = gocui.ColorCyan
+		layoutProperties(right)
+	} else if rightDetail == "entity" {
+		right.Highlight = true
+		right.SelFgColor = gocui.ColorCyan
+		layoutEditor(right)
+	} else if rightDetail == "batch" {
+		right.Highlight = true
+		right.SelFgColor = gocui.ColorCyan
+		layoutBatch(right)
 	} else {
 		right.Title = fmt.Sprintf("Contents of %s", leftSections[activeSection])
+		if name := selectedLeft(); name != "" {
+			right.Title = fmt.Sprintf("Contents of %s/%s", name, rightPrefix)
+		}
+		if rightLoading && len(rightData) > 0 {
+			right.Title += " (loading more…)"
+		} else if rightLoading {
+			right.Title += " (loading…)"
+		} else if rightNext != "" {
+			right.Title += fmt.Sprintf(" (%d loaded, more below)", len(rightData))
+		}
+		if q := activeTableQuery(selectedLeft()); leftKinds[activeSection] == storage.Tables && q != (storage.TableQuery{}) {
+			right.Title += fmt.Sprintf(" [$filter=%s $select=%s]", q.Filter, q.Select)
+		}
 		right.Highlight = true
 		right.SelFgColor = gocui.ColorCyan
 
-		current := leftSections[activeSection]
-		items := leftData[current]
-
-		if len(items) > 0 {
-			selected := items[activeLeftIndex]
-			if blobs, ok := rightData[selected]; ok {
-				for i, b := range blobs {
-					prefix := "  "
-					if focusSide == "right" && i == activeRightIndex {
-						prefix = "> "
-					}
-					fmt.Fprintf(right, "%s%s\n", prefix, b)
+		if selectedLeft() == "" {
+			fmt.Fprintln(right, "No items found.")
+		} else if rightErr != nil {
+			fmt.Fprintf(right, "Error: %v\n", rightErr)
+		} else if len(rightData) > 0 && leftKinds[activeSection] == storage.Tables {
+			right.Wrap = false
+			layoutGrid(right)
+		} else if len(rightData) > 0 {
+			for i, e := range rightData {
+				prefix := "  "
+				if focusSide == "right" && i == activeRightIndex {
+					prefix = "> "
 				}
-				if focusSide == "right" {
-					right.SetCursor(0, activeRightIndex)
-				} else {
-					right.SetCursor(0, 0)
+				line := e.Name
+				if e.Message != nil {
+					line = messageLine(e.Message)
 				}
+				fmt.Fprintf(right, "%s%s\n", prefix, line)
+			}
+			if focusSide == "right" {
+				scrollTo(right, activeRightIndex)
 			} else {
-				fmt.Fprintln(right, "No blobs or contents found.")
+				right.SetCursor(0, 0)
 			}
 		} else {
-			fmt.Fprintln(right, "No items found.")
+			fmt.Fprintln(right, "No blobs or contents found.")
 		}
 	}
 
@@ -112,29 +152,40 @@ func layout(g *gocui.Gui) error {
 		right.SetCursor(0, 0)
 	}
 
-	// --- Popup ---
-	if showPopup {
-		popupW, popupH := 60, 10
-		x0 := (maxX - popupW) / 2
-		y0 := (maxY - popupH) / 2
-		v, err := g.SetView("popup", x0, y0, x0+popupW, y0+popupH, 0)
-		if err != nil && !errors.Is(err, gocui.ErrUnknownView) {
-			return err
-		}
-		v.Title = "Welcome!"
-		v.Wrap = true
-		v.Clear()
-		fmt.Fprintln(v, "Welcome to Azurite Local Storage Explorer")
-		fmt.Fprintln(v, "")
-		fmt.Fprintln(v, "[H/L] Switch Resource Type")
-		fmt.Fprintln(v, "[J/K] Navigate")
-		fmt.Fprintln(v, "[Enter] Open Selected")
-		fmt.Fprintln(v, "[ESC] Return to Left Panel")
-		fmt.Fprintln(v, "[L] Toggle Logs | [R] Reattach Logs")
-		fmt.Fprintln(v, "[Q] Quit")
-	} else {
-		g.DeleteView("popup")
+	if err := layoutStatusBar(g, maxX, maxY); err != nil {
+		return err
+	}
+	return layoutModals(g, maxX, maxY)
+}
+
+// scrollTo keeps line idx of v on screen and puts the cursor on it.
+func scrollTo(v *gocui.View, idx int) {
+	_, h := v.Size()
+	oy := 0
+	if h > 0 && idx >= h {
+		oy = idx - h + 1
 	}
+	ox, _ := v.Origin()
+	v.SetOrigin(ox, oy)
+	v.SetCursor(0, idx-oy)
+}
 
+// welcomeText is the key help shown at startup and on ?.
+const welcomeText = `Welcome to Azurite Local Storage Explorer
+
+[H/L] Switch Resource Type | [N] New [Shift+D] Delete Selected
+[J/K] Navigate
+[Enter] Open Selected / Preview Blob
+[ESC] Up One Folder / Return to Left Panel
+[L] Toggle Logs | [R] Reattach Logs
+[F5] Refresh | [U] Upload File | [D] Download Blob | [C] Copy as JSON
+[P] Blob Properties & Metadata
+Queues: [A] Add [G] Dequeue [E] Update Front [X] Delete Front [Shift+X] Clear
+Tables: [F] Filter [I] Insert [E] Edit [X] Delete [H/L] Scroll [Shift+B] Batch
+Shares: [M] New Directory [U] Upload [D] Download File/Directory [P] Properties [X] Delete
+[Shift+A] Azurite Instances | [Shift+M] Manage Emulator | [?] This Help | [Q] Quit`
+
+func showHelp(g *gocui.Gui, v *gocui.View) error {
+	openMessage(g, "Welcome!", welcomeText)
 	return nil
 }
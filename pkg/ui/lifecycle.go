@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Emulator lifecycle ---
+// M manages the connected instance and A then Enter any other: start, stop,
+// restart, pause, resume, recreate, reset data and pull a newer image. The
+// menu also toggles whether quitting stops the emulator.
+
+// manageEmulator opens the lifecycle menu of the connected instance.
+func manageEmulator(g *gocui.Gui, v *gocui.View) error {
+	if len(modals) > 0 {
+		return nil
+	}
+	instanceActions(g, azurite, emulatorState)
+	return nil
+}
+
+// instanceActions offers what can be done with inst, given its state.
+func instanceActions(g *gocui.Gui, inst storage.AzuriteConfig, state string) {
+	connected := inst.Name == azurite.Name
+	var options []string
+	if !connected {
+		options = append(options, "Connect")
+	}
+	switch state {
+	case "running":
+		options = append(options, "Stop", "Restart", "Pause")
+	case "paused":
+		options = append(options, "Resume", "Stop")
+	default:
+		options = append(options, "Start")
+	}
+	options = append(options, "Recreate with current options", "Reset data…", "Pull newer image")
+	if connected {
+		leave := "off"
+		if config.LeaveRunning {
+			leave = "on"
+		}
+		options = append(options, "Leave running on quit: "+leave)
+	}
+
+	openPicker(g, fmt.Sprintf("Instance %s (%s)", inst.Name, state), options, 0, func(g *gocui.Gui, i int) error {
+		switch option := options[i]; {
+		case option == "Connect":
+			connectInstance(g, inst)
+		case option == "Start":
+			runLifecycle(g, inst, "Starting", "Started", ensure(inst))
+		case option == "Stop":
+			stop := func(g *gocui.Gui) error {
+				runAction(g, "Stopped "+inst.Name, func() error {
+					return storage.StopAzuriteContainer(inst)
+				}, nil)
+				return nil
+			}
+			if !connected {
+				return stop(g)
+			}
+			openConfirm(g, "Stop instance", fmt.Sprintf("Stop %s? The explorer is connected to it.", inst.Name), stop)
+		case option == "Restart":
+			runLifecycle(g, inst, "Restarting", "Restarted", func() error {
+				if err := storage.StopAzuriteContainer(inst); err != nil {
+					return err
+				}
+				return ensure(inst)()
+			})
+		case option == "Pause":
+			runAction(g, "Paused "+inst.Name, withRuntime(inst, storage.Runtime.Pause), nil)
+		case option == "Resume":
+			runLifecycle(g, inst, "Resuming", "Resumed", withRuntime(inst, storage.Runtime.Unpause))
+		case strings.HasPrefix(option, "Recreate"):
+			question := fmt.Sprintf("Remove and recreate %s with the current options? Data in %s is kept.", inst.Name, inst.Volume)
+			if inst.InMemory {
+				question = fmt.Sprintf("Remove and recreate %s with the current options? Its in-memory data is lost.", inst.Name)
+			}
+			openConfirm(g, "Recreate instance", question, func(g *gocui.Gui) error {
+				runLifecycle(g, inst, "Recreating", "Recreated", func() error {
+					if err := withRuntime(inst, storage.Runtime.Remove)(); err != nil {
+						return err
+					}
+					return ensure(inst)()
+				})
+				return nil
+			})
+		case strings.HasPrefix(option, "Reset"):
+			resetData(g, inst)
+		case strings.HasPrefix(option, "Pull"):
+			pullImage(g, inst)
+		case strings.HasPrefix(option, "Leave running"):
+			config.LeaveRunning = !config.LeaveRunning
+			if config.LeaveRunning {
+				statusMsg = "Quitting will leave every instance running"
+			} else {
+				statusMsg = "Quitting will stop the instances this session started or restarted"
+			}
+		}
+		return nil
+	})
+}
+
+// ensure returns a function that starts inst and waits until it is ready.
+func ensure(inst storage.AzuriteConfig) func() error {
+	return func() error {
+		_, err := storage.EnsureAzurite(inst, func(string) {})
+		return err
+	}
+}
+
+// withRuntime binds a Runtime method to inst's runtime.
+func withRuntime(inst storage.AzuriteConfig, method func(storage.Runtime, storage.AzuriteConfig) error) func() error {
+	return func() error {
+		rt, err := storage.RuntimeFor(inst)
+		if err != nil {
+			return err
+		}
+		return method(rt, inst)
+	}
+}
+
+// runLifecycle runs an action that (re)starts inst, then reattaches the logs
+// and reloads the explorer if it is the connected instance.
+func runLifecycle(g *gocui.Gui, inst storage.AzuriteConfig, doing, did string, fn func() error) {
+	setStatus(g, "%s %s…", doing, inst.Name)
+	runAction(g, did+" "+inst.Name, fn, func(g *gocui.Gui) {
+		if inst.Name != azurite.Name {
+			return
+		}
+		followLogs(g, func(ctx context.Context) (<-chan string, error) {
+			return storage.AttachLogs(ctx, inst)
+		})
+		serviceHealth = nil
+		go waitAndRefresh(g, backend)
+	})
+}
+
+// resetData asks which services to wipe and where to save a snapshot first,
+// then confirms and resets inst.
+func resetData(g *gocui.Gui, inst storage.AzuriteConfig) {
+	scopes := []string{"All services"}
+	for _, s := range storage.AzuriteServices {
+		scopes = append(scopes, "Only "+s)
+	}
+	openPicker(g, "Reset data of "+inst.Name, scopes, 0, func(g *gocui.Gui, i int) error {
+		var services []string
+		what := "all data"
+		if i > 0 {
+			services = storage.AzuriteServices[i-1 : i]
+			what = "the " + services[0] + " data"
+		}
+		reset := func(g *gocui.Gui, snapshot string) error {
+			question := fmt.Sprintf("Delete %s of %s and restart it? This cannot be undone.", what, inst.Name)
+			if snapshot != "" {
+				question = fmt.Sprintf("Save %s, then delete %s of %s and restart it?", snapshot, what, inst.Name)
+			}
+			openConfirm(g, "Reset data", question, func(g *gocui.Gui) error {
+				runLifecycle(g, inst, "Resetting", "Reset", func() error {
+					return storage.ResetAzuriteData(inst, services, snapshot, func(msg string) {
+						setStatus(g, "%s", strings.TrimSpace(msg))
+					})
+				})
+				return nil
+			})
+			return nil
+		}
+		if inst.InMemory {
+			return reset(g, "")
+		}
+		openPrompt(g, "Save a snapshot first to (.tar.gz, empty for none)", "", func(g *gocui.Gui, path string) error {
+			if path != "" {
+				path = expandHome(path)
+			}
+			return reset(g, path)
+		})
+		return nil
+	})
+}
+
+func pullImage(g *gocui.Gui, inst storage.AzuriteConfig) {
+	setStatus(g, "Pulling %s…", inst.ImageRef())
+	runAction(g, fmt.Sprintf("Pulled %s; recreate %s to use it", inst.ImageRef(), inst.Name), func() error {
+		rt, err := storage.RuntimeFor(inst)
+		if err != nil {
+			return err
+		}
+		return rt.Pull(inst, func(line string) {
+			setStatus(g, "Pulling %s: %s", inst.ImageRef(), strings.TrimSpace(line))
+		})
+	}, nil)
+}
@@ -1,7 +1,7 @@
 package ui
 
 import (
-	"time"
+	"context"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/Linux-DEX/azstorecli/pkg/storage"
@@ -23,31 +23,81 @@ func toggleLogs(g *gocui.Gui, v *gocui.View) error {
 }
 
 func reattachLogs(g *gocui.Gui, v *gocui.View) error {
-	id := storage.GetAzuriteContainerID()
-	newChan, _ := storage.AttachLogs(id)
-	logChan = newChan
-	logsBuf = []string{}
+	followLogs(g, func(ctx context.Context) (<-chan string, error) {
+		return storage.AttachLogs(ctx, azurite)
+	})
 	return nil
 }
 
+// followLogs points the log panel at the stream open starts, cancelling the
+// one it replaces so its reader exits. Called on the UI goroutine.
+func followLogs(g *gocui.Gui, open func(context.Context) (<-chan string, error)) {
+	if stopLogs != nil {
+		stopLogs()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := open(ctx)
+	logChan, stopLogs = ch, cancel
+	logsBuf = []string{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		listenLogs(g, ch)
+	}()
+}
+
 // --- Logs listener ---
-func listenLogs(g *gocui.Gui) {
+// listenLogs adds the lines of ch to the log panel until ch closes or the
+// explorer quits. Lines of a stream followLogs has replaced are dropped.
+func listenLogs(g *gocui.Gui, ch <-chan string) {
 	for {
+		var lines []string
 		select {
 		case <-done:
 			return
-		case line, ok := <-logChan:
+		case line, ok := <-ch:
 			if !ok {
+				g.Update(func(gui *gocui.Gui) error {
+					// Wait for reattachLogs or a newly connected instance to replace it
+					if logChan == ch {
+						logChan = nil
+					}
+					return nil
+				})
 				return
 			}
-			logsBuf = append(logsBuf, line)
+			lines = append(lines, line)
+		}
+		// Take whatever else is buffered into the same redraw
+	drain:
+		for len(lines) < 500 {
+			select {
+			case line, ok := <-ch:
+				if !ok {
+					break drain
+				}
+				lines = append(lines, line)
+			default:
+				break drain
+			}
+		}
+
+		added := make(chan struct{})
+		g.Update(func(gui *gocui.Gui) error {
+			defer close(added)
+			if logChan != ch {
+				return nil
+			}
+			logsBuf = append(logsBuf, lines...)
 			if len(logsBuf) > 500 {
 				logsBuf = logsBuf[len(logsBuf)-500:]
 			}
-			if showLogs {
-				g.UpdateAsync(func(gui *gocui.Gui) error { return nil })
-			}
-		case <-time.After(10 * time.Millisecond):
+			return nil
+		})
+		select {
+		case <-done:
+			return
+		case <-added:
 		}
 	}
 }
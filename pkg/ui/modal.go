@@ -0,0 +1,354 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Modals ---
+// Messages, yes/no confirmations, text forms and pickers drawn over the
+// panels. They stack: opening one from a callback puts it on top, and Esc
+// closes only the top one. Every modal view is editable so global letter
+// keys stay quiet while it has focus; modalEditor handles typing instead.
+// Only the keys bound to the view itself (see bindModalKeys) still fire.
+
+type modalKind int
+
+const (
+	modalMessage modalKind = iota
+	modalConfirm
+	modalForm
+	modalPicker
+)
+
+// formField is one labelled input line of a form.
+type formField struct {
+	label string
+	value string
+}
+
+type modal struct {
+	kind    modalKind
+	title   string
+	body    string      // text of messages and confirmations
+	fields  []formField // forms
+	options []string    // pickers
+	index   int         // focused field, highlighted option, or 0 = Yes / 1 = No
+	cursor  int         // rune offset in the focused field
+	view    string      // name of its view once drawn
+
+	onConfirm func(g *gocui.Gui) error
+	onForm    func(g *gocui.Gui, values []string) error
+	onPick    func(g *gocui.Gui, index int) error
+}
+
+var modals []*modal
+
+func pushModal(g *gocui.Gui, m *modal) {
+	modals = append(modals, m)
+	g.Update(func(gui *gocui.Gui) error { return nil })
+}
+
+// openMessage shows text until Enter or Esc.
+func openMessage(g *gocui.Gui, title, text string) {
+	pushModal(g, &modal{kind: modalMessage, title: title, body: text})
+}
+
+// openConfirm asks a yes/no question; onYes runs only if it is answered yes.
+// No is selected initially.
+func openConfirm(g *gocui.Gui, title, question string, onYes func(*gocui.Gui) error) {
+	pushModal(g, &modal{kind: modalConfirm, title: title, body: question, index: 1, onConfirm: onYes})
+}
+
+// openPrompt asks for a single line of text. onSubmit receives the trimmed
+// text when Enter is pressed; Esc closes the prompt without calling it.
+func openPrompt(g *gocui.Gui, title, initial string, onSubmit func(*gocui.Gui, string) error) {
+	openForm(g, title, []formField{{value: initial}}, func(g *gocui.Gui, values []string) error {
+		return onSubmit(g, values[0])
+	})
+}
+
+// openForm asks for several lines of text at once; Tab and the arrow keys
+// move between fields. onSubmit receives the trimmed values in field order.
+func openForm(g *gocui.Gui, title string, fields []formField, onSubmit func(*gocui.Gui, []string) error) {
+	m := &modal{kind: modalForm, title: title, fields: fields, onForm: onSubmit}
+	m.cursor = len([]rune(fields[0].value))
+	pushModal(g, m)
+}
+
+// openPicker lets the user choose one of options, starting at selected.
+func openPicker(g *gocui.Gui, title string, options []string, selected int, onPick func(*gocui.Gui, int) error) {
+	pushModal(g, &modal{kind: modalPicker, title: title, options: options, index: selected, onPick: onPick})
+}
+
+func (m *modal) hint() string {
+	switch m.kind {
+	case modalConfirm:
+		return " (y/n, Esc to cancel)"
+	case modalForm:
+		if len(m.fields) > 1 {
+			return " (Tab next field, Enter to confirm, Esc to cancel)"
+		}
+		return " (Enter to confirm, Esc to cancel)"
+	case modalPicker:
+		return " (j/k, Enter to choose, Esc to cancel)"
+	}
+	return " (Esc to close)"
+}
+
+// lines renders the modal's content and returns the cursor position in it.
+func (m *modal) lines() (lines []string, cx, cy int) {
+	switch m.kind {
+	case modalMessage:
+		lines = strings.Split(m.body, "\n")
+	case modalConfirm:
+		lines = append(strings.Split(m.body, "\n"), "")
+		yes, no := "  [ Yes ]", "  [ No ]"
+		if m.index == 0 {
+			yes = "> [ Yes ]"
+		} else {
+			no = "> [ No ]"
+		}
+		lines = append(lines, yes+"   "+no)
+	case modalForm:
+		width := 0
+		for _, f := range m.fields {
+			width = max(width, len([]rune(f.label)))
+		}
+		for i, f := range m.fields {
+			label := ""
+			if width > 0 {
+				label = fmt.Sprintf("%-*s ", width+1, f.label+":")
+			}
+			if i == m.index {
+				cx, cy = len([]rune(label))+m.cursor, i
+			}
+			lines = append(lines, label+f.value)
+		}
+	case modalPicker:
+		for i, o := range m.options {
+			prefix := "  "
+			if i == m.index {
+				prefix, cy = "> ", i
+			}
+			lines = append(lines, prefix+o)
+		}
+	}
+	return lines, cx, cy
+}
+
+// layoutModals draws the modal stack, bottom first, and focuses the top one.
+func layoutModals(g *gocui.Gui, maxX, maxY int) error {
+	for i, m := range modals {
+		lines, cx, cy := m.lines()
+		w := min(max(maxX*2/3, 40), maxX-2)
+		for _, l := range append(lines, m.title+m.hint()) {
+			w = max(w, min(len([]rune(l))+4, maxX-2))
+		}
+		h := min(len(lines)+1, maxY-2)
+		x0 := (maxX-w)/2 + i*2
+		y0 := (maxY-h)/2 + i
+		m.view = "modal" + strconv.Itoa(i)
+		v, err := g.SetView(m.view, x0, y0, x0+w, y0+h, 0)
+		if err != nil {
+			if !errors.Is(err, gocui.ErrUnknownView) {
+				return err
+			}
+			v.Editable = true
+			// Editable views skip letter bindings unless told otherwise; y and n need them
+			v.KeybindOnEdit = true
+			v.Editor = gocui.EditorFunc(modalEditor)
+			if err := bindModalKeys(g, m.view); err != nil {
+				return err
+			}
+		}
+		v.Title = m.title + m.hint()
+		v.Wrap = m.kind == modalMessage
+		v.Highlight = m.kind == modalPicker
+		v.SelFgColor = gocui.ColorCyan
+		v.Clear()
+		fmt.Fprint(v, strings.Join(lines, "\n"))
+
+		vw, vh := v.Size()
+		ox, oy := max(cx-vw+1, 0), max(cy-vh+1, 0)
+		v.SetOrigin(ox, oy)
+		v.SetCursor(cx-ox, cy-oy)
+		if _, err := g.SetViewOnTop(m.view); err != nil {
+			return err
+		}
+	}
+	if len(modals) == 0 {
+		return nil
+	}
+	top := modals[len(modals)-1]
+	g.Cursor = top.kind == modalForm
+	_, err := g.SetCurrentView(top.view)
+	return err
+}
+
+// bindModalKeys adds the keys a modal view handles itself; everything else
+// goes to modalEditor.
+func bindModalKeys(g *gocui.Gui, view string) error {
+	keys := []struct {
+		key interface{}
+		fn  func(*gocui.Gui, *gocui.View) error
+	}{
+		{gocui.KeyEnter, submitModal},
+		{gocui.KeyEsc, cancelModal},
+		{'y', answerModal(true)},
+		{'n', answerModal(false)},
+	}
+	for _, kb := range keys {
+		if err := g.SetKeybinding(view, kb.key, gocui.ModNone, kb.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// popModal closes the top modal and hands focus to the next one, or back to
+// the right panel so global keys work again.
+func popModal(g *gocui.Gui) *modal {
+	m := modals[len(modals)-1]
+	modals = modals[:len(modals)-1]
+	if m.view != "" {
+		g.DeleteView(m.view)
+		g.DeleteKeybindings(m.view)
+	}
+	g.Cursor = false
+	if len(modals) > 0 {
+		g.SetCurrentView(modals[len(modals)-1].view)
+	} else {
+		g.SetCurrentView("right")
+	}
+	return m
+}
+
+func submitModal(g *gocui.Gui, v *gocui.View) error {
+	if len(modals) == 0 {
+		return nil
+	}
+	m := popModal(g)
+	switch m.kind {
+	case modalConfirm:
+		if m.index == 0 && m.onConfirm != nil {
+			return m.onConfirm(g)
+		}
+	case modalForm:
+		values := make([]string, len(m.fields))
+		for i, f := range m.fields {
+			values[i] = strings.TrimSpace(f.value)
+		}
+		if m.onForm != nil {
+			return m.onForm(g, values)
+		}
+	case modalPicker:
+		if m.onPick != nil && m.index < len(m.options) {
+			return m.onPick(g, m.index)
+		}
+	}
+	return nil
+}
+
+func cancelModal(g *gocui.Gui, v *gocui.View) error {
+	if len(modals) > 0 {
+		popModal(g)
+	}
+	return nil
+}
+
+// answerModal handles y and n: they answer a confirmation and are ordinary
+// letters in a form.
+func answerModal(yes bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if len(modals) == 0 {
+			return nil
+		}
+		m := modals[len(modals)-1]
+		if m.kind != modalConfirm {
+			ch := 'n'
+			if yes {
+				ch = 'y'
+			}
+			modalEditor(v, 0, ch, gocui.ModNone)
+			return nil
+		}
+		if !yes {
+			popModal(g)
+			return nil
+		}
+		m.index = 0
+		return submitModal(g, v)
+	}
+}
+
+// modalEditor handles keys in the top modal: text editing in forms and
+// moving the selection in confirmations and pickers.
+func modalEditor(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	if len(modals) == 0 {
+		return
+	}
+	m := modals[len(modals)-1]
+	switch m.kind {
+	case modalConfirm:
+		switch {
+		case key == gocui.KeyTab || key == gocui.KeyArrowLeft || key == gocui.KeyArrowRight || ch == 'h' || ch == 'l':
+			m.index = 1 - m.index
+		}
+	case modalPicker:
+		switch {
+		case key == gocui.KeyArrowDown || key == gocui.KeyTab || ch == 'j':
+			m.index = min(m.index+1, len(m.options)-1)
+		case key == gocui.KeyArrowUp || ch == 'k':
+			m.index = max(m.index-1, 0)
+		}
+	case modalForm:
+		editField(m, key, ch)
+	}
+}
+
+func editField(m *modal, key gocui.Key, ch rune) {
+	f := &m.fields[m.index]
+	value := []rune(f.value)
+	m.cursor = min(m.cursor, len(value))
+	switch {
+	case key == gocui.KeyTab || key == gocui.KeyArrowDown:
+		m.index = (m.index + 1) % len(m.fields)
+		m.cursor = len([]rune(m.fields[m.index].value))
+		return
+	case key == gocui.KeyArrowUp:
+		m.index = (m.index + len(m.fields) - 1) % len(m.fields)
+		m.cursor = len([]rune(m.fields[m.index].value))
+		return
+	case key == gocui.KeyArrowLeft:
+		m.cursor = max(m.cursor-1, 0)
+	case key == gocui.KeyArrowRight:
+		m.cursor = min(m.cursor+1, len(value))
+	case key == gocui.KeyHome || key == gocui.KeyCtrlA:
+		m.cursor = 0
+	case key == gocui.KeyEnd || key == gocui.KeyCtrlE:
+		m.cursor = len(value)
+	case key == gocui.KeyCtrlU:
+		value, m.cursor = value[m.cursor:], 0
+	case key == gocui.KeyBackspace || key == gocui.KeyBackspace2:
+		if m.cursor > 0 {
+			value = append(value[:m.cursor-1], value[m.cursor:]...)
+			m.cursor--
+		}
+	case key == gocui.KeyDelete:
+		if m.cursor < len(value) {
+			value = append(value[:m.cursor], value[m.cursor+1:]...)
+		}
+	case key == gocui.KeySpace:
+		ch = ' '
+		fallthrough
+	case ch != 0:
+		value = append(value[:m.cursor], append([]rune{ch}, value[m.cursor:]...)...)
+		m.cursor++
+	}
+	f.value = string(value)
+}
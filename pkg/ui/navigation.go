@@ -1,15 +1,18 @@
 package ui
 
 import (
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
 	"github.com/awesome-gocui/gocui"
 )
 
 // --- Navigation ---
-// (No change to moveLeft, moveRight, moveDown, moveUp, selectItem, handleEsc)
 func moveLeft(g *gocui.Gui, v *gocui.View) error {
 	if focusSide == "left" && activeSection > 0 {
 		activeSection--
 		activeLeftIndex = 0
+		loadRight(g)
+	} else if focusSide == "right" && rightDetail == "" && leftKinds[activeSection] == storage.Tables {
+		scrollGrid(-8)
 	}
 	g.Update(func(gui *gocui.Gui) error { return nil })
 	return nil
@@ -19,6 +22,9 @@ func moveRight(g *gocui.Gui, v *gocui.View) error {
 	if focusSide == "left" && activeSection < len(leftSections)-1 {
 		activeSection++
 		activeLeftIndex = 0
+		loadRight(g)
+	} else if focusSide == "right" && rightDetail == "" && leftKinds[activeSection] == storage.Tables {
+		scrollGrid(8)
 	}
 	g.Update(func(gui *gocui.Gui) error { return nil })
 	return nil
@@ -29,24 +35,41 @@ func moveDown(g *gocui.Gui, v *gocui.View) error {
 	if showLogs { // If logs are shown, 'j' scrolls down the logs
 		return scrollLogsDown(g)
 	}
+	if rightDetail == "preview" {
+		previewOrigin++
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "properties" {
+		moveProperties(1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "entity" {
+		moveEditor(1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "batch" {
+		moveBatch(1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
 
 	if focusSide == "left" {
 		current := leftSections[activeSection]
 		items := leftData[current]
 		if activeLeftIndex < len(items)-1 {
 			activeLeftIndex++
+			loadRight(g)
 		}
 	} else if focusSide == "right" {
-		current := leftSections[activeSection]
-		items := leftData[current]
-		if len(items) == 0 {
-			return nil
-		}
-		selected := items[activeLeftIndex]
-		blobs := rightData[selected]
-		if activeRightIndex < len(blobs)-1 {
+		if activeRightIndex < len(rightData)-1 {
 			activeRightIndex++
 		}
+		if activeRightIndex >= len(rightData)-1 {
+			fetchMore(g)
+		}
 	}
 	g.Update(func(gui *gocui.Gui) error { return nil })
 	return nil
@@ -57,9 +80,32 @@ func moveUp(g *gocui.Gui, v *gocui.View) error {
 	if showLogs { // If logs are shown, 'k' scrolls up the logs
 		return scrollLogsUp(g)
 	}
+	if rightDetail == "preview" {
+		if previewOrigin > 0 {
+			previewOrigin--
+		}
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "properties" {
+		moveProperties(-1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "entity" {
+		moveEditor(-1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	if rightDetail == "batch" {
+		moveBatch(-1)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
 
 	if focusSide == "left" && activeLeftIndex > 0 {
 		activeLeftIndex--
+		loadRight(g)
 	} else if focusSide == "right" && activeRightIndex > 0 {
 		activeRightIndex--
 	}
@@ -72,15 +118,36 @@ func selectItem(g *gocui.Gui, v *gocui.View) error {
 	if focusSide == "left" && !showLogs {
 		focusSide = "right"
 		activeRightIndex = 0
+	} else if rightDetail == "properties" && !showLogs {
+		editProperty(g)
+	} else if rightDetail == "entity" && !showLogs {
+		activateEditorRow(g)
+	} else if rightDetail == "batch" && !showLogs {
+		activateBatchRow(g)
+	} else if focusSide == "right" && !showLogs && rightDetail == "" && activeRightIndex < len(rightData) {
+		// Descend into a virtual directory, or preview a blob
+		if e := rightData[activeRightIndex]; e.IsDir {
+			rightPrefix = e.Path
+			fetchRight(g)
+		} else if leftKinds[activeSection] == storage.Containers {
+			openPreview(g, selectedLeft(), e.Path)
+		} else if leftKinds[activeSection] == storage.Shares {
+			showFileProperties(g)
+		}
 	}
 	g.Update(func(gui *gocui.Gui) error { return nil })
 	return nil
 }
 
 func handleEsc(g *gocui.Gui, v *gocui.View) error {
-	if showPopup {
-		showPopup = false
-		g.DeleteView("popup")
+	if rightDetail == "batch" && !showLogs {
+		closeBatch(g)
+	} else if rightDetail != "" && !showLogs {
+		closeDetail()
+	} else if focusSide == "right" && !showLogs && rightPrefix != "" {
+		// Go up one virtual directory before leaving the right panel
+		rightPrefix = storage.ParentPrefix(rightPrefix)
+		fetchRight(g)
 	} else if focusSide == "right" && !showLogs {
 		focusSide = "left"
 		activeRightIndex = 0
@@ -88,3 +155,65 @@ func handleEsc(g *gocui.Gui, v *gocui.View) error {
 	g.Update(func(gui *gocui.Gui) error { return nil })
 	return nil
 }
+
+// editItem edits the selected right-panel item of the active section.
+func editItem(g *gocui.Gui, v *gocui.View) error {
+	switch leftKinds[activeSection] {
+	case storage.Queues:
+		return updateMessage(g, v)
+	case storage.Tables:
+		editEntityItem(g)
+	}
+	g.Update(func(gui *gocui.Gui) error { return nil })
+	return nil
+}
+
+// deleteItem deletes the selected right-panel item of the active section,
+// the selected property while the entity editor is open, or the selected
+// operation while the batch is open.
+func deleteItem(g *gocui.Gui, v *gocui.View) error {
+	if rightDetail == "entity" {
+		removeEditorProperty()
+	} else if rightDetail == "batch" {
+		unstageBatchOp()
+	} else {
+		switch leftKinds[activeSection] {
+		case storage.Queues:
+			return deleteMessage(g, v)
+		case storage.Tables:
+			deleteEntityItem(g)
+		case storage.Shares:
+			deleteShareItem(g)
+		}
+	}
+	g.Update(func(gui *gocui.Gui) error { return nil })
+	return nil
+}
+
+// uploadItem uploads a local file into the container or share directory shown.
+func uploadItem(g *gocui.Gui, v *gocui.View) error {
+	if leftKinds[activeSection] == storage.Shares {
+		uploadShareFile(g)
+		return nil
+	}
+	return uploadBlob(g, v)
+}
+
+// downloadItem downloads the selected blob or file.
+func downloadItem(g *gocui.Gui, v *gocui.View) error {
+	if leftKinds[activeSection] == storage.Shares {
+		downloadShareFile(g)
+		return nil
+	}
+	return downloadBlob(g, v)
+}
+
+// propertiesItem shows the properties of the selected blob, file or directory.
+func propertiesItem(g *gocui.Gui, v *gocui.View) error {
+	if leftKinds[activeSection] == storage.Shares {
+		showFileProperties(g)
+		g.Update(func(gui *gocui.Gui) error { return nil })
+		return nil
+	}
+	return showProperties(g, v)
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// previewLimit is how much of a blob is fetched for the preview pane.
+const previewLimit = 64 << 10
+
+// --- Blob preview ---
+// While rightDetail is "preview" the right panel shows previewBody instead of the listing.
+var (
+	previewTitle  string
+	previewBody   string
+	previewOrigin int
+)
+
+func openPreview(g *gocui.Gui, container, blob string) {
+	rightDetail = "preview"
+	previewTitle = fmt.Sprintf("Preview of %s/%s (loading…)", container, blob)
+	previewBody = ""
+	previewOrigin = 0
+	seq := rightSeq
+	b := backend
+	go func() {
+		data, props, err := b.ReadBlob(container, blob, previewLimit)
+		g.Update(func(gui *gocui.Gui) error {
+			if seq != rightSeq || rightDetail != "preview" {
+				return nil
+			}
+			if err != nil {
+				previewTitle = fmt.Sprintf("Preview of %s/%s", container, blob)
+				previewBody = fmt.Sprintf("Error: %v", err)
+				return nil
+			}
+			format, body := renderPreview(data, props.ContentType)
+			size := formatSize(props.ContentLength)
+			if int64(len(data)) < props.ContentLength {
+				size += ", truncated to the first " + formatSize(int64(len(data)))
+			}
+			previewTitle = fmt.Sprintf("Preview of %s/%s [%s, %s]", container, blob, format, size)
+			previewBody = body
+			return nil
+		})
+	}()
+}
+
+// renderPreview picks JSON, text or hex rendering from the content type and
+// the data itself, returning the chosen format and the rendered text.
+func renderPreview(data []byte, contentType string) (string, string) {
+	if len(data) == 0 {
+		return "empty", "(empty blob)"
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(data)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	looksJSON := strings.Contains(contentType, "json") ||
+		(len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['))
+	if looksJSON && json.Valid(trimmed) {
+		var out bytes.Buffer
+		if json.Indent(&out, trimmed, "", "  ") == nil {
+			return "json", out.String()
+		}
+	}
+
+	if isText(data) {
+		return "text", strings.ReplaceAll(string(data), "\r\n", "\n")
+	}
+	return "hex", hex.Dump(data)
+}
+
+// isText reports whether data is UTF-8 without control characters other than
+// whitespace. A rune cut off by the preview limit at the end is tolerated.
+func isText(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// formatSize renders a byte count as B, KiB, MiB or GiB.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 2; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMG"[exp])
+}
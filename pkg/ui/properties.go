@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Blob properties ---
+// While rightDetail is "properties" the right panel lists the properties of
+// propsBlob. HTTP header and metadata rows can be edited with Enter.
+var (
+	propsContainer string
+	propsBlob      string
+	propsData      storage.BlobProperties
+	propsErr       error
+	propsLoaded    bool
+	propsIndex     int
+)
+
+// propRow is one line of the properties view.
+type propRow struct {
+	label   string
+	value   string
+	setter  func(h *storage.BlobHTTPHeaders, v string) // HTTP header rows
+	metaKey string                                     // metadata rows
+	add     bool                                       // the "add metadata" row
+}
+
+func (r propRow) editable() bool {
+	return r.setter != nil || r.metaKey != "" || r.add
+}
+
+func showProperties(g *gocui.Gui, v *gocui.View) error {
+	container, blob, ok := selectedBlob()
+	if !ok || rightDetail != "" {
+		return nil
+	}
+	rightDetail = "properties"
+	propsContainer, propsBlob = container, blob
+	propsIndex = 0
+	loadProperties(g)
+	return nil
+}
+
+func loadProperties(g *gocui.Gui) {
+	propsLoaded = false
+	container, blob := propsContainer, propsBlob
+	seq := rightSeq
+	b := backend
+	go func() {
+		props, err := b.GetBlobProperties(container, blob)
+		g.Update(func(gui *gocui.Gui) error {
+			if seq != rightSeq || rightDetail != "properties" {
+				return nil
+			}
+			propsData, propsErr, propsLoaded = props, err, true
+			return nil
+		})
+	}()
+}
+
+func propRows() []propRow {
+	p := propsData
+	lastModified := ""
+	if !p.LastModified.IsZero() {
+		lastModified = p.LastModified.Local().Format(time.RFC1123)
+	}
+	rows := []propRow{
+		{label: "Content-Type", value: p.ContentType, setter: func(h *storage.BlobHTTPHeaders, v string) { h.ContentType = v }},
+		{label: "Content-Length", value: fmt.Sprintf("%d (%s)", p.ContentLength, formatSize(p.ContentLength))},
+		{label: "ETag", value: p.ETag},
+		{label: "Last-Modified", value: lastModified},
+		{label: "Blob Type", value: p.BlobType},
+		{label: "Access Tier", value: p.AccessTier},
+		{label: "Lease State", value: p.LeaseState + " / " + p.LeaseStatus},
+		{label: "Content-Encoding", value: p.ContentEncoding, setter: func(h *storage.BlobHTTPHeaders, v string) { h.ContentEncoding = v }},
+		{label: "Content-Language", value: p.ContentLanguage, setter: func(h *storage.BlobHTTPHeaders, v string) { h.ContentLanguage = v }},
+		{label: "Content-Disposition", value: p.ContentDisposition, setter: func(h *storage.BlobHTTPHeaders, v string) { h.ContentDisposition = v }},
+		{label: "Cache-Control", value: p.CacheControl, setter: func(h *storage.BlobHTTPHeaders, v string) { h.CacheControl = v }},
+		{label: "Content-MD5", value: p.ContentMD5, setter: func(h *storage.BlobHTTPHeaders, v string) { h.ContentMD5 = v }},
+	}
+	keys := make([]string, 0, len(p.Metadata))
+	for k := range p.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rows = append(rows, propRow{label: "meta: " + k, value: p.Metadata[k], metaKey: k})
+	}
+	return append(rows, propRow{label: "+ Add metadata", add: true})
+}
+
+// layoutProperties renders the properties view into the right panel.
+func layoutProperties(v *gocui.View) {
+	v.Title = fmt.Sprintf("Properties of %s/%s (Enter to edit, Esc to close)", propsContainer, propsBlob)
+	if !propsLoaded {
+		fmt.Fprintln(v, "Loading…")
+		return
+	}
+	if propsErr != nil {
+		fmt.Fprintf(v, "Error: %v\n", propsErr)
+		return
+	}
+	rows := propRows()
+	if propsIndex >= len(rows) {
+		propsIndex = len(rows) - 1
+	}
+	for i, r := range rows {
+		prefix := "  "
+		if i == propsIndex {
+			prefix = "> "
+		}
+		if r.add {
+			fmt.Fprintf(v, "%s%s\n", prefix, r.label)
+		} else {
+			fmt.Fprintf(v, "%s%-20s %s\n", prefix, r.label+":", r.value)
+		}
+	}
+	scrollTo(v, propsIndex)
+}
+
+func moveProperties(delta int) {
+	n := len(propRows())
+	propsIndex += delta
+	if propsIndex < 0 {
+		propsIndex = 0
+	}
+	if propsIndex >= n {
+		propsIndex = n - 1
+	}
+}
+
+// editProperty prompts for a new value of the selected row and saves it.
+func editProperty(g *gocui.Gui) {
+	if !propsLoaded || propsErr != nil {
+		return
+	}
+	rows := propRows()
+	if propsIndex >= len(rows) || !rows[propsIndex].editable() {
+		return
+	}
+	row := rows[propsIndex]
+	container, blob, props, b := propsContainer, propsBlob, propsData, backend
+
+	if row.setter != nil {
+		openPrompt(g, "Set "+row.label, row.value, func(g *gocui.Gui, value string) error {
+			headers := props.BlobHTTPHeaders
+			row.setter(&headers, value)
+			saveProperties(g, func() error {
+				return b.SetBlobHTTPHeaders(container, blob, headers, props.ETag)
+			}, "Saved "+row.label)
+			return nil
+		})
+		return
+	}
+
+	initial, title := "", "Add metadata (key=value)"
+	if !row.add {
+		initial = row.metaKey + "=" + row.value
+		title = "Edit metadata (key=value, empty to delete)"
+	}
+	openPrompt(g, title, initial, func(g *gocui.Gui, value string) error {
+		meta := map[string]string{}
+		for k, v := range props.Metadata {
+			meta[k] = v
+		}
+		delete(meta, row.metaKey)
+		if value != "" {
+			k, v, ok := strings.Cut(value, "=")
+			// Names are read back lowercased, so store them that way too
+			k = strings.ToLower(strings.TrimSpace(k))
+			if !ok || k == "" {
+				setStatus(g, "Metadata must be key=value")
+				return nil
+			}
+			if err := storage.ValidateMetadataName(k); err != nil {
+				setStatus(g, "%v", err)
+				return nil
+			}
+			meta[k] = strings.TrimSpace(v)
+		} else if row.add {
+			return nil
+		}
+		saveProperties(g, func() error {
+			return b.SetBlobMetadata(container, blob, meta, props.ETag)
+		}, "Saved metadata")
+		return nil
+	})
+}
+
+// saveProperties runs save in the background and reloads the view afterwards.
+func saveProperties(g *gocui.Gui, save func() error, okMsg string) {
+	runAction(g, okMsg, save, func(gui *gocui.Gui) {
+		if rightDetail == "properties" {
+			loadProperties(gui)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Queue messages ---
+
+// messageLine renders a peeked message as a row of the right panel.
+func messageLine(m *storage.QueueMessage) string {
+	id := m.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	body := strings.ReplaceAll(m.Body, "\n", " ")
+	return fmt.Sprintf("%s  %s  dq:%-3d %-6s %s", id, m.InsertionTime.Local().Format("2006-01-02 15:04:05"), m.DequeueCount, m.Encoding, body)
+}
+
+// selectedQueue returns the highlighted queue, if the Queues section is active.
+func selectedQueue() (string, bool) {
+	if showLogs || leftKinds[activeSection] != storage.Queues {
+		return "", false
+	}
+	name := selectedLeft()
+	return name, name != ""
+}
+
+// selectedMessage returns the message under the right cursor.
+func selectedMessage() (string, *storage.QueueMessage, bool) {
+	queue, ok := selectedQueue()
+	if !ok || focusSide != "right" || rightDetail != "" || activeRightIndex >= len(rightData) {
+		return "", nil, false
+	}
+	m := rightData[activeRightIndex].Message
+	return queue, m, m != nil
+}
+
+// frontMessage is selectedMessage for the actions that need a pop receipt,
+// which can only be had for the front message without dequeuing the others.
+func frontMessage(g *gocui.Gui) (string, *storage.QueueMessage, bool) {
+	queue, m, ok := selectedMessage()
+	if ok && activeRightIndex != 0 {
+		setStatus(g, "Only the front message can be changed; dequeue the ones before it first")
+		return "", nil, false
+	}
+	return queue, m, ok
+}
+
+func addMessage(g *gocui.Gui, v *gocui.View) error {
+	queue, ok := selectedQueue()
+	if !ok {
+		return nil
+	}
+	fields := []formField{
+		{label: "Text"},
+		{label: "Visibility timeout (s)", value: "0"},
+		{label: "TTL (s, -1 = never)", value: "604800"},
+		{label: "Encoding (base64|text)", value: "base64"},
+	}
+	openForm(g, "New message for "+queue, fields, func(g *gocui.Gui, values []string) error {
+		text := values[0]
+		if text == "" {
+			return nil
+		}
+		visibility, ttl, enc, err := parseMessageOptions(values[1], values[2], values[3])
+		if err != nil {
+			setStatus(g, "%v", err)
+			return nil
+		}
+		b := backend
+		runAction(g, fmt.Sprintf("Added %s message to %s", enc, queue), func() error {
+			return b.PutMessage(queue, storage.EncodeMessage(text, enc), visibility, ttl)
+		}, refetchIf(queue))
+		return nil
+	})
+	return nil
+}
+
+// parseMessageOptions reads the visibility timeout and TTL in seconds and the
+// encoding name. Empty fields default to 0, the service TTL and base64.
+func parseMessageOptions(visibility, ttl, encoding string) (time.Duration, time.Duration, storage.MessageEncoding, error) {
+	secs := []int{0, 0}
+	for i, field := range []string{visibility, ttl} {
+		if field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid number %q", field)
+		}
+		secs[i] = n
+	}
+	enc := storage.EncodingBase64
+	if encoding != "" {
+		var err error
+		if enc, err = storage.ParseMessageEncoding(encoding); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	ttlDur := time.Duration(secs[1]) * time.Second
+	if secs[1] < 0 {
+		ttlDur = -1
+	}
+	return time.Duration(secs[0]) * time.Second, ttlDur, enc, nil
+}
+
+func dequeueMessage(g *gocui.Gui, v *gocui.View) error {
+	queue, ok := selectedQueue()
+	if !ok {
+		return nil
+	}
+	b := backend
+	go func() {
+		m, err := b.DequeueMessage(queue)
+		if err != nil {
+			setStatus(g, "Error: %v", err)
+		} else {
+			setStatus(g, "Dequeued %s (%s): %s", m.ID, m.Encoding, m.Body)
+		}
+		g.Update(func(gui *gocui.Gui) error {
+			refetchIf(queue)(gui)
+			return nil
+		})
+	}()
+	return nil
+}
+
+func updateMessage(g *gocui.Gui, v *gocui.View) error {
+	queue, m, ok := frontMessage(g)
+	if !ok {
+		return nil
+	}
+	id, enc := m.ID, m.Encoding
+	openPrompt(g, fmt.Sprintf("New text for message %s (kept %s)", id, enc), m.Body, func(g *gocui.Gui, text string) error {
+		b := backend
+		runAction(g, "Updated message "+id, func() error {
+			return b.UpdateMessage(queue, id, storage.EncodeMessage(text, enc))
+		}, refetchIf(queue))
+		return nil
+	})
+	return nil
+}
+
+func deleteMessage(g *gocui.Gui, v *gocui.View) error {
+	queue, m, ok := frontMessage(g)
+	if !ok {
+		return nil
+	}
+	id := m.ID
+	openConfirm(g, "Delete message", fmt.Sprintf("Delete message %s from %s?", id, queue), func(g *gocui.Gui) error {
+		b := backend
+		runAction(g, "Deleted message "+id, func() error {
+			return b.DeleteMessage(queue, id)
+		}, refetchIf(queue))
+		return nil
+	})
+	return nil
+}
+
+func clearQueue(g *gocui.Gui, v *gocui.View) error {
+	queue, ok := selectedQueue()
+	if !ok {
+		return nil
+	}
+	openConfirm(g, "Clear queue", fmt.Sprintf("Delete ALL messages in %s?", queue), func(g *gocui.Gui) error {
+		b := backend
+		runAction(g, "Cleared "+queue, func() error {
+			return b.ClearMessages(queue)
+		}, refetchIf(queue))
+		return nil
+	})
+	return nil
+}
@@ -10,6 +10,11 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 	if done != nil {
 		close(done)
 	}
-	storage.StopAzurite()
+	if stopLogs != nil {
+		stopLogs()
+	}
+	if !config.LeaveRunning {
+		storage.StopAzurite()
+	}
 	return gocui.ErrQuit
 }
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Resource management ---
+// n and D on the left panel create and delete containers, queues, shares and tables.
+
+// resourceNoun is the singular name of the active section's resources.
+func resourceNoun(kind storage.ResourceKind) string {
+	switch kind {
+	case storage.Containers:
+		return "container"
+	case storage.Queues:
+		return "queue"
+	case storage.Shares:
+		return "share"
+	}
+	return "table"
+}
+
+func newResource(g *gocui.Gui, v *gocui.View) error {
+	if showLogs || focusSide != "left" {
+		return nil
+	}
+	kind := leftKinds[activeSection]
+	fields := []formField{{label: "Name"}}
+	switch kind {
+	case storage.Containers:
+		fields = append(fields, formField{label: "Public access (private|blob|container)", value: "private"})
+	case storage.Queues:
+		fields = append(fields, formField{label: "Metadata (key=value, ...)"})
+	case storage.Shares:
+		fields = append(fields, formField{label: "Quota in GiB (empty for default)"})
+	}
+	openResourceForm(g, kind, "New "+resourceNoun(kind), fields)
+	return nil
+}
+
+// openResourceForm shows the creation form, opening it again with the reason
+// while the name breaks the naming rules or an option is invalid.
+func openResourceForm(g *gocui.Gui, kind storage.ResourceKind, title string, fields []formField) {
+	openForm(g, title, fields, func(g *gocui.Gui, values []string) error {
+		name := values[0]
+		if name == "" {
+			return nil
+		}
+		for i := range fields {
+			fields[i].value = values[i]
+		}
+		create, err := resourceCreator(kind, name, values[1:])
+		if err == nil {
+			err = storage.ValidateName(kind, name)
+		}
+		if err != nil {
+			openResourceForm(g, kind, fmt.Sprintf("Invalid: %v", err), fields)
+			return nil
+		}
+		runAction(g, "Created "+resourceNoun(kind)+" "+name, create, func(gui *gocui.Gui) {
+			refreshLeftTo(gui, name)
+		})
+		return nil
+	})
+}
+
+// resourceCreator parses the per-type options and returns the call that
+// creates the resource.
+func resourceCreator(kind storage.ResourceKind, name string, options []string) (func() error, error) {
+	b := backend
+	switch kind {
+	case storage.Containers:
+		for _, a := range storage.PublicAccessLevels {
+			if strings.EqualFold(options[0], a.String()) {
+				return func() error { return b.CreateContainer(name, a) }, nil
+			}
+		}
+		return nil, fmt.Errorf("access level %q is not private, blob or container", options[0])
+	case storage.Queues:
+		meta, err := parseMetadataList(options[0])
+		if err != nil {
+			return nil, err
+		}
+		return func() error { return b.CreateQueue(name, meta) }, nil
+	case storage.Shares:
+		quota := 0
+		if options[0] != "" {
+			n, err := strconv.Atoi(options[0])
+			if err != nil || n < 1 || n > 102400 {
+				return nil, fmt.Errorf("quota must be a whole number of GiB from 1 to 102400")
+			}
+			quota = n
+		}
+		return func() error { return b.CreateShare(name, quota) }, nil
+	}
+	return func() error { return b.CreateTable(name) }, nil
+}
+
+// parseMetadataList parses "a=1, b=2" into metadata, checking each name.
+func parseMetadataList(s string) (map[string]string, error) {
+	meta := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok {
+			return nil, fmt.Errorf("metadata %q must be key=value", strings.TrimSpace(pair))
+		}
+		if err := storage.ValidateMetadataName(k); err != nil {
+			return nil, err
+		}
+		meta[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	return meta, nil
+}
+
+func deleteResource(g *gocui.Gui, v *gocui.View) error {
+	if showLogs || focusSide != "left" {
+		return nil
+	}
+	kind, name := leftKinds[activeSection], selectedLeft()
+	if name == "" {
+		return nil
+	}
+	noun := resourceNoun(kind)
+	openConfirm(g, "Delete "+noun, fmt.Sprintf("Delete %s %s and everything in it?", noun, name), func(g *gocui.Gui) error {
+		b := backend
+		runAction(g, "Deleted "+noun+" "+name, func() error {
+			switch kind {
+			case storage.Containers:
+				return b.DeleteContainer(name)
+			case storage.Queues:
+				return b.DeleteQueue(name)
+			case storage.Shares:
+				return b.DeleteShare(name)
+			}
+			return b.DeleteTable(name)
+		}, refreshLeft)
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Status bar ---
+// Row 0 describes the emulator and the connection, the last row shows where
+// the explorer is and the result of the last action. watchEmulator keeps the
+// emulator fields current.
+var (
+	emulatorID      string
+	emulatorState   string // runtime state, "" until the first check
+	emulatorVersion string
+	connection      string // account and endpoint in use
+)
+
+// describeAccount names an account by its name and blob endpoint host.
+func describeAccount(a storage.Account) string {
+	u, err := url.Parse(a.BlobEndpoint)
+	if err != nil || u.Host == "" {
+		return a.Name
+	}
+	return a.Name + " @ " + u.Host
+}
+
+// watchEmulator polls the state of the connected Azurite instance until quit.
+func watchEmulator(g *gocui.Gui) {
+	var lastName, lastID, version string
+	for {
+		inst, ok := connectedInstance(g)
+		if !ok {
+			return
+		}
+		id, state, err := storage.AzuriteStatus(inst)
+		switch {
+		case err != nil:
+			state = "unknown"
+		case state == "":
+			state = "not created"
+		}
+		if inst.Name != lastName || id != lastID || version == "" {
+			version = storage.AzuriteVersion(inst)
+			lastName, lastID = inst.Name, id
+		}
+		g.Update(func(gui *gocui.Gui) error {
+			if inst.Name == azurite.Name {
+				emulatorID, emulatorState, emulatorVersion = id, state, version
+			}
+			return nil
+		})
+		select {
+		case <-done:
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// connectedInstance reads azurite on the UI goroutine, as connectInstance
+// replaces it there. ok is false once the explorer quits.
+func connectedInstance(g *gocui.Gui) (inst storage.AzuriteConfig, ok bool) {
+	current := make(chan storage.AzuriteConfig, 1)
+	g.Update(func(gui *gocui.Gui) error {
+		current <- azurite
+		return nil
+	})
+	select {
+	case inst = <-current:
+		return inst, true
+	case <-done:
+		return inst, false
+	}
+}
+
+// emulatorStatus is the runtime state, shown as "starting" until every
+// service has answered.
+func emulatorStatus() string {
+	if emulatorState == "" {
+		return "starting"
+	}
+	if emulatorState != "running" {
+		return emulatorState
+	}
+	if len(serviceHealth) == 0 {
+		return "starting"
+	}
+	for _, h := range serviceHealth {
+		if !h.Ready {
+			return "starting"
+		}
+	}
+	return "running"
+}
+
+// breadcrumb is the path to what the right panel shows.
+func breadcrumb() string {
+	if showLogs {
+		return "Logs"
+	}
+	parts := []string{leftSections[activeSection]}
+	if name := selectedLeft(); name != "" {
+		parts = append(parts, name)
+		for _, dir := range strings.Split(strings.TrimSuffix(rightPrefix, "/"), "/") {
+			if dir != "" {
+				parts = append(parts, dir)
+			}
+		}
+	}
+	if rightDetail != "" {
+		parts = append(parts, rightDetail)
+	}
+	return strings.Join(parts, " › ")
+}
+
+func layoutStatusBar(g *gocui.Gui, maxX, maxY int) error {
+	id := emulatorID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	fields := []string{"Azurite " + azurite.Name, emulatorStatus()}
+	if id != "" {
+		fields = append(fields, id)
+	}
+	if emulatorVersion != "" {
+		fields = append(fields, emulatorVersion)
+	}
+	if len(serviceHealth) > 0 {
+		fields = append(fields, healthSummary(serviceHealth))
+	}
+	fields = append(fields, connection)
+	if err := statusLine(g, "header", 0, maxX, strings.Join(fields, " │ ")); err != nil {
+		return err
+	}
+
+	footer := breadcrumb()
+	if statusMsg != "" {
+		footer += " │ " + statusMsg
+	}
+	return statusLine(g, "footer", maxY-1, maxX, footer)
+}
+
+// statusLine draws text on screen row y as a frameless view.
+func statusLine(g *gocui.Gui, name string, y, maxX int, text string) error {
+	v, err := g.SetView(name, -1, y-1, maxX, y+1, 0)
+	if err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Frame = false
+		v.FgColor = gocui.ColorBlack
+		v.BgColor = gocui.ColorCyan
+	}
+	v.Clear()
+	fmt.Fprint(v, " "+text)
+	return nil
+}
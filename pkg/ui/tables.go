@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// maxColumnWidth caps a grid column; longer values are cut with "…".
+const maxColumnWidth = 32
+
+// --- Table entities ---
+// The Tables section renders entities as a grid. tableQuery applies only while
+// tableQueryFor names the selected table; gridOffsetX scrolls the grid sideways.
+var (
+	tableQuery    storage.TableQuery
+	tableQueryFor string
+	gridOffsetX   int
+)
+
+// activeTableQuery returns the filter set for table, if any.
+func activeTableQuery(table string) storage.TableQuery {
+	if tableQueryFor != table {
+		return storage.TableQuery{}
+	}
+	return tableQuery
+}
+
+func filterEntities(g *gocui.Gui, v *gocui.View) error {
+	if showLogs || leftKinds[activeSection] != storage.Tables {
+		return nil
+	}
+	table := selectedLeft()
+	if table == "" {
+		return nil
+	}
+	current := activeTableQuery(table)
+	fields := []formField{
+		{label: "$filter", value: current.Filter},
+		{label: "$select", value: current.Select},
+	}
+	openForm(g, "Query "+table+", e.g. PartitionKey eq 'a' and Age gt 30; $select comma-separated", fields, func(g *gocui.Gui, values []string) error {
+		tableQuery = storage.TableQuery{Filter: values[0], Select: strings.ReplaceAll(values[1], " ", "")}
+		tableQueryFor = table
+		if selectedLeft() == table {
+			fetchRight(g)
+		}
+		return nil
+	})
+	return nil
+}
+
+// gridColumns returns the system columns followed by every property seen in
+// entries (or the $select order when one is set), with the Edm type of each.
+func gridColumns(entries []storage.Entry, sel string) ([]string, map[string]storage.EdmType) {
+	types := map[string]storage.EdmType{}
+	var props []string
+	for _, e := range entries {
+		if e.Entity == nil {
+			continue
+		}
+		for _, name := range e.Entity.PropertyNames() {
+			if _, ok := types[name]; !ok {
+				types[name] = e.Entity.Properties[name].Type
+				props = append(props, name)
+			}
+		}
+	}
+	if sel != "" {
+		props = props[:0]
+		for _, name := range strings.Split(sel, ",") {
+			if name != "PartitionKey" && name != "RowKey" && name != "Timestamp" && name != "" {
+				props = append(props, name)
+			}
+		}
+	} else {
+		sort.Strings(props)
+	}
+	return append([]string{"PartitionKey", "RowKey", "Timestamp"}, props...), types
+}
+
+func gridCell(e *storage.Entity, column string) string {
+	switch column {
+	case "PartitionKey":
+		return e.PartitionKey
+	case "RowKey":
+		return e.RowKey
+	case "Timestamp":
+		if e.Timestamp.IsZero() {
+			return ""
+		}
+		return e.Timestamp.Local().Format("2006-01-02 15:04:05")
+	}
+	return e.Properties[column].Value
+}
+
+// layoutGrid writes rightData as a header line plus one aligned row per entity.
+func layoutGrid(v *gocui.View) {
+	columns, types := gridColumns(rightData, activeTableQuery(selectedLeft()).Select)
+	headers := make([]string, len(columns))
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		headers[i] = c
+		if t, ok := types[c]; ok {
+			headers[i] = fmt.Sprintf("%s (%s)", c, strings.TrimPrefix(string(t), "Edm."))
+		}
+		widths[i] = len([]rune(headers[i]))
+	}
+	for _, e := range rightData {
+		for i, c := range columns {
+			if n := len([]rune(gridCell(e.Entity, c))); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for i := range widths {
+		widths[i] = min(widths[i], maxColumnWidth)
+	}
+
+	row := func(prefix string, cells []string) {
+		var b strings.Builder
+		b.WriteString(prefix)
+		for i, cell := range cells {
+			b.WriteString(padCell(cell, widths[i]))
+			b.WriteString("  ")
+		}
+		fmt.Fprintln(v, strings.TrimRight(b.String(), " "))
+	}
+	row("  ", headers)
+	for i, e := range rightData {
+		prefix := "  "
+		if focusSide == "right" && i == activeRightIndex {
+			prefix = "> "
+		}
+		cells := make([]string, len(columns))
+		for j, c := range columns {
+			cells[j] = strings.ReplaceAll(gridCell(e.Entity, c), "\n", " ")
+		}
+		row(prefix, cells)
+	}
+
+	v.SetOrigin(gridOffsetX, 0)
+	if focusSide == "right" {
+		scrollTo(v, activeRightIndex+1) // +1 for the header line
+	} else {
+		v.SetCursor(0, 0)
+	}
+}
+
+// padCell cuts s to width runes (marking the cut with "…") and pads it with spaces.
+func padCell(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		r = append(r[:width-1], '…')
+	}
+	return string(r) + strings.Repeat(" ", width-len(r))
+}
+
+// scrollGrid moves the table grid sideways by dx columns.
+func scrollGrid(dx int) {
+	gridOffsetX = max(gridOffsetX+dx, 0)
+}
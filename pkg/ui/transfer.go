@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Linux-DEX/azstorecli/pkg/storage"
+	"github.com/awesome-gocui/gocui"
+)
+
+// --- Transfers ---
+
+// setStatus records the outcome of the last operation. Safe to call from goroutines.
+func setStatus(g *gocui.Gui, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	g.Update(func(gui *gocui.Gui) error {
+		statusMsg = msg
+		return nil
+	})
+}
+
+// expandHome turns a leading "~/" into the user's home directory.
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+func uploadBlob(g *gocui.Gui, v *gocui.View) error {
+	if showLogs || leftKinds[activeSection] != storage.Containers {
+		return nil
+	}
+	container := selectedLeft()
+	if container == "" {
+		return nil
+	}
+	prefix := rightPrefix
+	openPrompt(g, fmt.Sprintf("Upload local file to %s/%s", container, prefix), "", func(g *gocui.Gui, path string) error {
+		if path == "" {
+			return nil
+		}
+		path = expandHome(path)
+		blob := prefix + filepath.Base(path)
+		b := backend
+		go func() {
+			err := b.UploadFile(container, blob, path, storage.DefaultUploadOptions(), func(done, total int64) {
+				if total > 0 {
+					setStatus(g, "Uploading %s: %d%%", blob, done*100/total)
+				}
+			})
+			if err != nil {
+				setStatus(g, "Upload of %s failed: %v", blob, err)
+				return
+			}
+			setStatus(g, "Uploaded %s", blob)
+			g.Update(func(gui *gocui.Gui) error {
+				if selectedLeft() == container && rightPrefix == prefix {
+					fetchRight(gui)
+				}
+				return nil
+			})
+		}()
+		return nil
+	})
+	return nil
+}
+
+// selectedBlob returns the container and blob under the right cursor, if the
+// right panel is focused on a blob (not a virtual directory).
+func selectedBlob() (container, blob string, ok bool) {
+	if focusSide != "right" || showLogs || leftKinds[activeSection] != storage.Containers {
+		return "", "", false
+	}
+	if activeRightIndex >= len(rightData) || rightData[activeRightIndex].IsDir {
+		return "", "", false
+	}
+	return selectedLeft(), rightData[activeRightIndex].Path, true
+}
+
+func downloadBlob(g *gocui.Gui, v *gocui.View) error {
+	container, blob, ok := selectedBlob()
+	if !ok {
+		return nil
+	}
+	initial := "./" + filepath.Base(blob)
+	openPrompt(g, fmt.Sprintf("Download %s/%s to", container, blob), initial, func(g *gocui.Gui, path string) error {
+		if path == "" {
+			return nil
+		}
+		path = expandHome(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, filepath.Base(blob))
+		}
+		b := backend
+		go func() {
+			err := b.DownloadFile(container, blob, path, func(done, total int64) {
+				if total > 0 {
+					setStatus(g, "Downloading %s: %d%%", blob, done*100/total)
+				}
+			})
+			if err != nil {
+				setStatus(g, "Download of %s failed: %v", blob, err)
+				return
+			}
+			setStatus(g, "Downloaded %s to %s", blob, path)
+		}()
+		return nil
+	})
+	return nil
+}